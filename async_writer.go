@@ -0,0 +1,202 @@
+package ledge
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+type asyncItem struct {
+	entry *Entry
+	// flushed is set instead of entry for flush markers, and is closed once
+	// every Entry queued before the marker has been written
+	flushed chan struct{}
+}
+
+type asyncWriter struct {
+	writeEntry     func(*Entry)
+	queueSize      int
+	overflowPolicy OverflowPolicy
+	items          []*asyncItem
+	numEntries     int
+	dropped        uint64
+	closed         bool
+	lock           *sync.Mutex
+	cond           *sync.Cond
+	done           chan struct{}
+	// writeLock is held while an Entry is written, so that other writes to the
+	// writer, such as of Fatal and Panic Entry objects, do not interleave
+	writeLock *sync.Mutex
+}
+
+func newAsyncWriter(
+	writeEntry func(*Entry),
+	options AsyncLoggerOptions,
+) *asyncWriter {
+	queueSize := options.QueueSize
+	if queueSize <= 0 {
+		queueSize = DefaultAsyncQueueSize
+	}
+	lock := &sync.Mutex{}
+	asyncWriter := &asyncWriter{
+		writeEntry,
+		queueSize,
+		options.OverflowPolicy,
+		make([]*asyncItem, 0, queueSize),
+		0,
+		0,
+		false,
+		lock,
+		sync.NewCond(lock),
+		make(chan struct{}),
+		&sync.Mutex{},
+	}
+	go asyncWriter.run()
+	return asyncWriter
+}
+
+func (a *asyncWriter) enqueue(entry *Entry) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	for !a.closed && a.numEntries >= a.queueSize {
+		switch a.overflowPolicy {
+		case OverflowPolicyDropNewest:
+			a.dropped++
+			return
+		case OverflowPolicyDropOldest:
+			a.dropOldest()
+		default:
+			a.cond.Wait()
+		}
+	}
+	if a.closed {
+		a.dropped++
+		return
+	}
+	a.items = append(a.items, &asyncItem{entry: entry})
+	a.numEntries++
+	a.cond.Broadcast()
+}
+
+func (a *asyncWriter) dropOldest() {
+	for i, item := range a.items {
+		if item.entry != nil {
+			a.items = append(a.items[:i], a.items[i+1:]...)
+			a.numEntries--
+			a.dropped++
+			return
+		}
+	}
+}
+
+func (a *asyncWriter) flush(timeout time.Duration) error {
+	a.lock.Lock()
+	flushed := make(chan struct{})
+	if a.closed {
+		close(flushed)
+	} else {
+		a.items = append(a.items, &asyncItem{flushed: flushed})
+		a.cond.Broadcast()
+	}
+	a.lock.Unlock()
+	if timeout <= 0 {
+		<-flushed
+		return nil
+	}
+	select {
+	case <-flushed:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("ledge: timed out after %v flushing queued entries", timeout)
+	}
+}
+
+func (a *asyncWriter) close() error {
+	a.lock.Lock()
+	if a.closed {
+		a.lock.Unlock()
+		return nil
+	}
+	a.closed = true
+	a.cond.Broadcast()
+	a.lock.Unlock()
+	<-a.done
+	return nil
+}
+
+func (a *asyncWriter) getDropped() uint64 {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.dropped
+}
+
+func (a *asyncWriter) run() {
+	for {
+		a.lock.Lock()
+		for len(a.items) == 0 && !a.closed {
+			a.cond.Wait()
+		}
+		if len(a.items) == 0 {
+			a.lock.Unlock()
+			close(a.done)
+			return
+		}
+		item := a.items[0]
+		a.items[0] = nil
+		a.items = a.items[1:]
+		if item.entry != nil {
+			a.numEntries--
+		}
+		a.cond.Broadcast()
+		a.lock.Unlock()
+		if item.entry != nil {
+			a.writeLock.Lock()
+			a.writeEntry(item.entry)
+			a.writeLock.Unlock()
+		} else {
+			close(item.flushed)
+		}
+	}
+}
+
+type asyncLogger struct {
+	*logger
+	asyncWriter *asyncWriter
+}
+
+func newAsyncLogger(
+	writer io.Writer,
+	marshaller Marshaller,
+	reflectTypeProvider *reflectTypeProvider,
+	options LoggerOptions,
+	asyncOptions AsyncLoggerOptions,
+) *asyncLogger {
+	logger := newLogger(
+		writer,
+		marshaller,
+		reflectTypeProvider,
+		options,
+		"",
+		make([]Context, 0),
+		nil,
+	)
+	asyncWriter := newAsyncWriter(logger.writeQueuedEntry, asyncOptions)
+	logger.asyncWriter = asyncWriter
+	return &asyncLogger{
+		logger,
+		asyncWriter,
+	}
+}
+
+func (a *asyncLogger) Flush(timeout time.Duration) error {
+	return a.asyncWriter.flush(timeout)
+}
+
+func (a *asyncLogger) Close() error {
+	return a.asyncWriter.close()
+}
+
+func (a *asyncLogger) Dropped() uint64 {
+	return a.asyncWriter.getDropped()
+}
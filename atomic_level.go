@@ -0,0 +1,157 @@
+package ledge
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type atomicLevel struct {
+	value *int32
+}
+
+func newAtomicLevel(
+	level Level,
+) *atomicLevel {
+	value := int32(level)
+	return &atomicLevel{
+		&value,
+	}
+}
+
+func (a *atomicLevel) Level() Level {
+	return Level(atomic.LoadInt32(a.value))
+}
+
+func (a *atomicLevel) SetLevel(level Level) {
+	atomic.StoreInt32(a.value, int32(level))
+}
+
+func (a *atomicLevel) Include(entry *Entry) bool {
+	return a.Level() <= entry.Level
+}
+
+func (a *atomicLevel) ServeHTTP(responseWriter http.ResponseWriter, request *http.Request) {
+	switch request.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var levelRequest struct {
+			Level string `json:"level"`
+		}
+		if err := json.NewDecoder(request.Body).Decode(&levelRequest); err != nil {
+			writeLevelError(responseWriter, http.StatusBadRequest, err)
+			return
+		}
+		level, err := parseLevel(levelRequest.Level)
+		if err != nil {
+			writeLevelError(responseWriter, http.StatusBadRequest, err)
+			return
+		}
+		a.SetLevel(level)
+	default:
+		writeLevelError(responseWriter, http.StatusMethodNotAllowed, fmt.Errorf("ledge: method %s not allowed", request.Method))
+		return
+	}
+	writeLevelJSON(responseWriter, http.StatusOK, map[string]string{"level": levelString(a.Level())})
+}
+
+type levelRegistry struct {
+	defaultLevel      Level
+	lock              *sync.RWMutex
+	nameToAtomicLevel map[string]*atomicLevel
+}
+
+func newLevelRegistry(
+	defaultLevel Level,
+) *levelRegistry {
+	return &levelRegistry{
+		defaultLevel,
+		&sync.RWMutex{},
+		make(map[string]*atomicLevel),
+	}
+}
+
+func (l *levelRegistry) AtomicLevel(name string) AtomicLevel {
+	return l.getAtomicLevel(name)
+}
+
+func (l *levelRegistry) Levels() map[string]Level {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	levels := make(map[string]Level, len(l.nameToAtomicLevel))
+	for name, atomicLevel := range l.nameToAtomicLevel {
+		levels[name] = atomicLevel.Level()
+	}
+	return levels
+}
+
+func (l *levelRegistry) ServeHTTP(responseWriter http.ResponseWriter, request *http.Request) {
+	switch request.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var levelRequest struct {
+			Name  string `json:"name"`
+			Level string `json:"level"`
+		}
+		if err := json.NewDecoder(request.Body).Decode(&levelRequest); err != nil {
+			writeLevelError(responseWriter, http.StatusBadRequest, err)
+			return
+		}
+		level, err := parseLevel(levelRequest.Level)
+		if err != nil {
+			writeLevelError(responseWriter, http.StatusBadRequest, err)
+			return
+		}
+		l.getAtomicLevel(levelRequest.Name).SetLevel(level)
+	default:
+		writeLevelError(responseWriter, http.StatusMethodNotAllowed, fmt.Errorf("ledge: method %s not allowed", request.Method))
+		return
+	}
+	levels := make(map[string]string)
+	for name, level := range l.Levels() {
+		levels[name] = levelString(level)
+	}
+	writeLevelJSON(responseWriter, http.StatusOK, map[string]map[string]string{"levels": levels})
+}
+
+func (l *levelRegistry) getAtomicLevel(name string) *atomicLevel {
+	l.lock.RLock()
+	atomicLevel, ok := l.nameToAtomicLevel[name]
+	l.lock.RUnlock()
+	if ok {
+		return atomicLevel
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if atomicLevel, ok := l.nameToAtomicLevel[name]; ok {
+		return atomicLevel
+	}
+	atomicLevel = newAtomicLevel(l.defaultLevel)
+	l.nameToAtomicLevel[name] = atomicLevel
+	return atomicLevel
+}
+
+func parseLevel(s string) (Level, error) {
+	level, ok := Level_value[strings.ToUpper(s)]
+	if !ok {
+		return Level_NONE, fmt.Errorf("ledge: unknown level %s", s)
+	}
+	return Level(level), nil
+}
+
+func levelString(level Level) string {
+	return strings.ToLower(level.String())
+}
+
+func writeLevelError(responseWriter http.ResponseWriter, statusCode int, err error) {
+	writeLevelJSON(responseWriter, statusCode, map[string]string{"error": err.Error()})
+}
+
+func writeLevelJSON(responseWriter http.ResponseWriter, statusCode int, value interface{}) {
+	responseWriter.Header().Set("Content-Type", "application/json")
+	responseWriter.WriteHeader(statusCode)
+	_ = json.NewEncoder(responseWriter).Encode(value)
+}
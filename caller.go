@@ -0,0 +1,88 @@
+package ledge
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+const (
+	maxCallerDepth = 64
+)
+
+var (
+	packageDir = getPackageDir()
+	// adapters that call into this package, so that their callers are reported instead
+	adapterFunctionPrefixes = []string{
+		"log.",
+		"log/slog.",
+		"github.com/sirupsen/logrus.",
+	}
+	adapterLock = &sync.RWMutex{}
+)
+
+func registerAdapterPackage(packagePath string) {
+	adapterLock.Lock()
+	defer adapterLock.Unlock()
+
+	adapterFunctionPrefixes = append(adapterFunctionPrefixes, packagePath+".")
+}
+
+func getPackageDir() string {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		return ""
+	}
+	return filepath.Dir(file)
+}
+
+// getCaller returns the first caller outside of this package, so that the global
+// functions, UnstructuredLogger and Writer functions all report the calling code.
+func getCaller() *Caller {
+	pcs := make([]uintptr, maxCallerDepth)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !isPackageFile(frame.File) && !isAdapterFunction(frame.Function) {
+			return &Caller{frame.File, frame.Line, frame.Function}
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+// tests are in the package too, but they are callers like any other
+func isPackageFile(file string) bool {
+	return filepath.Dir(file) == packageDir && !strings.HasSuffix(file, "_test.go")
+}
+
+func isAdapterFunction(function string) bool {
+	adapterLock.RLock()
+	defer adapterLock.RUnlock()
+
+	for _, adapterFunctionPrefix := range adapterFunctionPrefixes {
+		if strings.HasPrefix(function, adapterFunctionPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// shortCallerString returns the Caller with only the last directory of the file
+// and no package path for the function, for text output.
+func shortCallerString(caller *Caller) string {
+	file := caller.File
+	if i := strings.LastIndex(file, "/"); i >= 0 {
+		if j := strings.LastIndex(file[:i], "/"); j >= 0 {
+			file = file[j+1:]
+		}
+	}
+	function := caller.Function
+	if i := strings.LastIndex(function, "/"); i >= 0 {
+		function = function[i+1:]
+	}
+	return fmt.Sprintf("%s:%d:%s", file, caller.Line, function)
+}
@@ -0,0 +1,12 @@
+/*
+Command ledge reads, converts and filters streams of marshalled Entry objects.
+
+See the documentation for package ledgecmd for usage.
+*/
+package main
+
+import "github.com/codeship/go-ledge/ledgecmd"
+
+func main() {
+	ledgecmd.Main()
+}
@@ -1,13 +1,60 @@
 package ledge
 
-import "bufio"
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
 
 var (
-	rpcDecoderInstance = &rpcDecoder{}
+	rpcDecoderInstance             = &rpcDecoder{}
+	lengthDelimitedDecoderInstance = &lengthDelimitedDecoder{}
 )
 
 type rpcDecoder struct{}
 
 func (r *rpcDecoder) Decode(bufReader *bufio.Reader) ([]byte, error) {
+	if isLengthDelimited(bufReader) {
+		return lengthDelimitedDecoderInstance.Decode(bufReader)
+	}
 	return bufReader.ReadSlice(separator)
 }
+
+type lengthDelimitedDecoder struct{}
+
+func (l *lengthDelimitedDecoder) Decode(bufReader *bufio.Reader) ([]byte, error) {
+	marker, err := bufReader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if marker != lengthDelimitedMarker {
+		return nil, fmt.Errorf("ledge: expected length-delimited frame marker, got %q", marker)
+	}
+	size, err := binary.ReadUvarint(bufReader)
+	if err != nil {
+		return nil, noEOF(err)
+	}
+	if size > maxLengthDelimitedSize {
+		return nil, fmt.Errorf("ledge: length-delimited frame of %d bytes exceeds maximum of %d", size, maxLengthDelimitedSize)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(bufReader, data); err != nil {
+		return nil, noEOF(err)
+	}
+	return data, nil
+}
+
+// isLengthDelimited returns true if the next frame was written with LengthDelimitedEncoder.
+func isLengthDelimited(bufReader *bufio.Reader) bool {
+	p, err := bufReader.Peek(1)
+	return err == nil && p[0] == lengthDelimitedMarker
+}
+
+// noEOF returns io.ErrUnexpectedEOF for io.EOF, as a frame that was started must be finished.
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,165 @@
+package ledge
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+const (
+	ecsVersion = "8.11.0"
+)
+
+var (
+	ecsMarshallerInstance = newECSMarshaller()
+)
+
+type ecsMarshaller struct {
+	jsonMarshaller *jsonMarshaller
+}
+
+func newECSMarshaller() *ecsMarshaller {
+	return &ecsMarshaller{
+		newJSONMarshaller(JSONMarshallerOptions{}),
+	}
+}
+
+func (e *ecsMarshaller) Marshal(entry *Entry) ([]byte, error) {
+	m := map[string]interface{}{
+		"@timestamp":  entry.Time.UTC().Format(time.RFC3339Nano),
+		"log.level":   strings.ToLower(entry.Level.String()),
+		"ecs.version": ecsVersion,
+		"event.id":    entry.ID,
+	}
+	if message := getEntryMessage(entry); message != "" {
+		m["message"] = message
+	}
+	labels := make(map[string]interface{})
+	for _, context := range entry.Contexts {
+		contextKey, err := textMarshallerObjectKeyString(context)
+		if err != nil {
+			return nil, err
+		}
+		if err := flattenJSONObject(contextKey, context, "_", func(key string, value interface{}) {
+			labels[key] = value
+		}); err != nil {
+			return nil, err
+		}
+	}
+	eventKey, eventValue, err := e.jsonMarshaller.getKeyValue(entry.Event)
+	if err != nil {
+		return nil, err
+	}
+	m["event.dataset"] = eventKey
+	if unstructuredEvent, ok := entry.Event.(*UnstructuredEvent); ok {
+		for key, fieldValue := range unstructuredEvent.Fields {
+			labels[strings.Replace(key, ".", "_", -1)] = getFieldValueJSONValue(fieldValue)
+		}
+	} else {
+		// custom fields are capitalized in ECS to not clash with future ECS fields, as type names usually are
+		m[eventKey] = eventValue
+	}
+	if errorEvent, ok := entry.Event.(*ErrorEvent); ok {
+		m["error.message"] = errorEvent.Msg
+		if errorEvent.TypeName != "" {
+			m["error.type"] = errorEvent.TypeName
+		}
+	}
+	if stack := getEntryStack(entry); stack != "" {
+		m["error.stack_trace"] = stack
+	}
+	if len(labels) > 0 {
+		m["labels"] = labels
+	}
+	if entry.Caller != nil {
+		m["log.origin.file.name"] = entry.Caller.File
+		m["log.origin.file.line"] = entry.Caller.Line
+		m["log.origin.function"] = entry.Caller.Function
+	}
+	return json.Marshal(m)
+}
+
+// getEntryMessage returns the writer output, or the message of an UnstructuredEvent or ErrorEvent.
+func getEntryMessage(entry *Entry) string {
+	if len(entry.WriterOutput) > 0 {
+		return trimRightSpace(string(entry.WriterOutput))
+	}
+	if msgEvent, ok := entry.Event.(interface {
+		GetMsg() string
+	}); ok {
+		return msgEvent.GetMsg()
+	}
+	return ""
+}
+
+// getEntryStack returns the stack of the Entry and the stacks of an *ErrorEvent, one after another.
+func getEntryStack(entry *Entry) string {
+	var stacks []string
+	if entry.Stack != "" {
+		stacks = append(stacks, trimRightSpace(entry.Stack))
+	}
+	if errorEvent, ok := entry.Event.(*ErrorEvent); ok {
+		for _, stack := range errorEventStacks(errorEvent) {
+			stacks = append(stacks, trimRightSpace(stack))
+		}
+	}
+	return strings.Join(stacks, "\n")
+}
+
+// getFieldValueJSONValue returns the value as marshalled by FieldValue.MarshalJSON.
+func getFieldValueJSONValue(fieldValue *FieldValue) interface{} {
+	switch fieldValue.GetType() {
+	case FieldType_STRING:
+		return fieldValue.GetStringValue()
+	case FieldType_FLOAT:
+		if s, ok := nonFiniteFloatString(fieldValue.GetFloatValue()); ok {
+			return s
+		}
+		return fieldValue.GetFloatValue()
+	case FieldType_INT, FieldType_BOOL:
+		return fieldValue.Value()
+	default:
+		return fieldValueString(fieldValue)
+	}
+}
+
+// flattenJSONObject calls f with every value of the JSON of the object, with the keys of nested
+// objects joined by separator. Values are strings, json.Numbers, bools or []interface{}, and
+// null values are left out. Raw data that is not JSON is a base64 string.
+func flattenJSONObject(key string, object interface{}, separator string, f func(string, interface{})) error {
+	var data []byte
+	if _, format, rawData, ok := rawObject(object); ok {
+		if format != RawFormatJSON {
+			f(key, base64.StdEncoding.EncodeToString(rawData))
+			return nil
+		}
+		data = rawData
+	} else {
+		var err error
+		if data, err = json.Marshal(object); err != nil {
+			return err
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	flattenJSONValue(key, value, separator, f)
+	return nil
+}
+
+func flattenJSONValue(key string, value interface{}, separator string, f func(string, interface{})) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for objectKey, objectValue := range value {
+			flattenJSONValue(key+separator+objectKey, objectValue, separator, f)
+		}
+	case nil:
+	default:
+		f(key, value)
+	}
+}
@@ -1,10 +1,23 @@
 package ledge
 
-import "io"
+import (
+	"encoding/binary"
+	"io"
+)
 
 var (
-	rpcEncoderInstance = &rpcEncoder{}
-	separator          = byte('\n')
+	rpcEncoderInstance             = &rpcEncoder{}
+	lengthDelimitedEncoderInstance = &lengthDelimitedEncoder{}
+	separator                      = byte('\n')
+)
+
+const (
+	// lengthDelimitedMarker starts every length-delimited frame. It can not start a line
+	// written with RPCEncoder, so decoders can tell the framings apart.
+	lengthDelimitedMarker = byte(0)
+	// maxLengthDelimitedSize is the maximum size of a length-delimited frame, so that
+	// corrupted data does not allocate arbitrary amounts of memory.
+	maxLengthDelimitedSize = 64 * 1024 * 1024
 )
 
 type rpcEncoder struct{}
@@ -12,3 +25,13 @@ type rpcEncoder struct{}
 func (r *rpcEncoder) Encode(writer io.Writer, data []byte) (int, error) {
 	return writer.Write(append(data, separator))
 }
+
+type lengthDelimitedEncoder struct{}
+
+func (l *lengthDelimitedEncoder) Encode(writer io.Writer, data []byte) (int, error) {
+	frame := make([]byte, 1+binary.MaxVarintLen64, 1+binary.MaxVarintLen64+len(data))
+	frame[0] = lengthDelimitedMarker
+	n := binary.PutUvarint(frame[1:], uint64(len(data)))
+	// one call to Write, so that frames are not interleaved or split across rotated files
+	return writer.Write(append(frame[:1+n], data...))
+}
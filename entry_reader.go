@@ -3,6 +3,7 @@ package ledge
 import (
 	"bufio"
 	"io"
+	"sync"
 )
 
 const (
@@ -11,11 +12,13 @@ const (
 
 type entryReader struct {
 	reader       *bufio.Reader
+	followReader *followReader
 	unmarshaller Unmarshaller
 	decoder      Decoder
 	options      EntryReaderOptions
 	output       chan *EntryResponse
-	cancel       chan bool
+	cancel       chan struct{}
+	cancelOnce   *sync.Once
 }
 
 func newEntryReader(
@@ -24,13 +27,21 @@ func newEntryReader(
 	decoder Decoder,
 	options EntryReaderOptions,
 ) (*entryReader, error) {
+	cancel := make(chan struct{})
+	var followReader *followReader
+	if options.Follow {
+		followReader = newFollowReader(reader, options.FollowInterval, cancel)
+		reader = followReader
+	}
 	obj := &entryReader{
 		bufio.NewReaderSize(reader, readerSize),
+		followReader,
 		unmarshaller,
 		decoder,
 		options,
 		make(chan *EntryResponse),
-		make(chan bool),
+		cancel,
+		&sync.Once{},
 	}
 	go obj.read()
 	return obj, nil
@@ -41,20 +52,22 @@ func (e *entryReader) Channel() <-chan *EntryResponse {
 }
 
 func (e *entryReader) Cancel() error {
-	e.cancel <- true
+	e.cancelOnce.Do(func() { close(e.cancel) })
 	return nil
 }
 
 func (e *entryReader) read() {
+	defer close(e.output)
+	if e.followReader != nil {
+		defer func() { _ = e.followReader.Close() }()
+	}
 	for {
 		select {
 		case <-e.cancel:
-			close(e.output)
 			return
 		default:
 			ok := e.keepReading()
 			if !ok {
-				close(e.output)
 				return
 			}
 		}
@@ -67,20 +80,27 @@ func (e *entryReader) keepReading() bool {
 		if err == io.EOF {
 			return false
 		}
-		e.output <- &EntryResponse{Error: err}
-		return true
+		return e.send(&EntryResponse{Error: err})
 	}
 	entry, err := e.unmarshaller.Unmarshal(data)
 	if err != nil {
-		e.output <- &EntryResponse{Error: err}
-		return true
+		return e.send(&EntryResponse{Error: err})
 	}
 	if e.include(entry) {
-		e.output <- &EntryResponse{Entry: entry}
+		return e.send(&EntryResponse{Entry: entry})
 	}
 	return true
 }
 
+func (e *entryReader) send(entryResponse *EntryResponse) bool {
+	select {
+	case e.output <- entryResponse:
+		return true
+	case <-e.cancel:
+		return false
+	}
+}
+
 func (e *entryReader) include(entry *Entry) bool {
 	return includeEntry(e.options.Filters, entry)
 }
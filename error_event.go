@@ -0,0 +1,149 @@
+package ledge
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	maxErrorCauses = 100
+)
+
+func newErrorEvent(err error) *ErrorEvent {
+	errorEvent := &ErrorEvent{
+		Msg:      err.Error(),
+		TypeName: getErrorTypeName(err),
+		Stack:    getErrorStack(err),
+	}
+	addErrorCauses(errorEvent, err, 1)
+	return errorEvent
+}
+
+func addErrorCauses(errorEvent *ErrorEvent, err error, depth int32) {
+	for _, cause := range unwrapError(err) {
+		// a badly behaved Unwrap could otherwise loop forever
+		if len(errorEvent.Causes) >= maxErrorCauses {
+			return
+		}
+		if cause == nil {
+			continue
+		}
+		errorEvent.Causes = append(
+			errorEvent.Causes,
+			&ErrorCause{
+				Depth:    depth,
+				Msg:      cause.Error(),
+				TypeName: getErrorTypeName(cause),
+				Stack:    getErrorStack(cause),
+			},
+		)
+		addErrorCauses(errorEvent, cause, depth+1)
+	}
+}
+
+// unwrapError handles both errors.Unwrap and errors.Join style wrapping.
+func unwrapError(err error) []error {
+	switch err := err.(type) {
+	case interface {
+		Unwrap() []error
+	}:
+		return err.Unwrap()
+	case interface {
+		Unwrap() error
+	}:
+		if cause := err.Unwrap(); cause != nil {
+			return []error{cause}
+		}
+	}
+	return nil
+}
+
+func getErrorTypeName(err error) string {
+	return fmt.Sprintf("%T", err)
+}
+
+// getErrorStack returns the stack trace of errors that have a StackTrace method, such
+// as those created by github.com/pkg/errors, formatted with %+v. Every package returns its
+// own stack type, which no interface in this package can name, so the method is looked up
+// with reflection. It is not called on nil receivers, and a panic in it is not propagated.
+func getErrorStack(err error) (stack string) {
+	value := reflect.ValueOf(err)
+	if isNilValue(value) {
+		return ""
+	}
+	method := value.MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return ""
+	}
+	defer func() {
+		if recover() != nil {
+			stack = ""
+		}
+	}()
+	result := method.Call(nil)[0]
+	if isNilValue(result) {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprintf("%+v", result.Interface()))
+}
+
+func isNilValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return value.IsNil()
+	default:
+		return false
+	}
+}
+
+// errorEventString renders the error and its causes on one line, with <- pointing
+// from an error to the errors it wraps, and [] around errors wrapped together.
+func errorEventString(errorEvent *ErrorEvent) string {
+	s := errorString(errorEvent.Msg, errorEvent.TypeName)
+	if causesString, _ := errorCausesString(errorEvent.Causes, 0, 1); causesString != "" {
+		s = fmt.Sprintf("%s <- %s", s, causesString)
+	}
+	return fmt.Sprintf("{%s}", s)
+}
+
+func errorCausesString(causes []*ErrorCause, i int, depth int32) (string, int) {
+	var causeStrings []string
+	for i < len(causes) && causes[i].Depth == depth {
+		causeString := errorString(causes[i].Msg, causes[i].TypeName)
+		var childrenString string
+		childrenString, i = errorCausesString(causes, i+1, depth+1)
+		if childrenString != "" {
+			causeString = fmt.Sprintf("%s <- %s", causeString, childrenString)
+		}
+		causeStrings = append(causeStrings, causeString)
+	}
+	if len(causeStrings) > 1 {
+		return fmt.Sprintf("[%s]", strings.Join(causeStrings, ", ")), i
+	}
+	return strings.Join(causeStrings, ""), i
+}
+
+func errorString(msg string, typeName string) string {
+	if typeName == "" {
+		return fmt.Sprintf("%q", msg)
+	}
+	return fmt.Sprintf("%q (%s)", msg, typeName)
+}
+
+// errorEventStacks returns the stack traces of the error and its causes, each
+// preceded by a line naming the error.
+func errorEventStacks(errorEvent *ErrorEvent) []string {
+	var stacks []string
+	if errorEvent.Stack != "" {
+		stacks = append(stacks, fmt.Sprintf("%s:\n%s", errorString(errorEvent.Msg, errorEvent.TypeName), errorEvent.Stack))
+	}
+	for _, cause := range errorEvent.Causes {
+		if cause.Stack != "" {
+			stacks = append(stacks, fmt.Sprintf("%s:\n%s", errorString(cause.Msg, cause.TypeName), cause.Stack))
+		}
+	}
+	return stacks
+}
@@ -12,6 +12,7 @@ type fakeLogger struct {
 	Logger
 	BlockingEntryReader
 	*fakeTimer
+	*fakeExiter
 }
 
 func newFakeLogger(
@@ -33,6 +34,7 @@ func newFakeLogger(
 	}
 	fakeIDAllocator := newFakeIDAllocator()
 	fakeTimer := newFakeTimer(0)
+	fakeExiter := newFakeExiter()
 	logger, err := NewLogger(
 		buffer,
 		ProtoMarshaller,
@@ -41,6 +43,7 @@ func newFakeLogger(
 			IDAllocator: fakeIDAllocator,
 			Timer:       fakeTimer,
 			Encoder:     RPCEncoder,
+			ExitFunc:    fakeExiter.Exit,
 		},
 	)
 	if err != nil {
@@ -50,6 +53,7 @@ func newFakeLogger(
 		logger,
 		NewBlockingEntryReader(entryReader),
 		fakeTimer,
+		fakeExiter,
 	}, nil
 }
 
@@ -79,6 +83,33 @@ func (ti *fakeIDAllocator) Allocate() string {
 	return fmt.Sprintf("%d", atomic.AddInt64(&ti.value, 1))
 }
 
+type fakeExiter struct {
+	code   int
+	exited bool
+	lock   *sync.Mutex
+}
+
+func newFakeExiter() *fakeExiter {
+	return &fakeExiter{
+		0,
+		false,
+		&sync.Mutex{},
+	}
+}
+
+func (f *fakeExiter) Exit(code int) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.code = code
+	f.exited = true
+}
+
+func (f *fakeExiter) ExitCode() (int, bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.code, f.exited
+}
+
 type fakeTimer struct {
 	now int64
 }
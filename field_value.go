@@ -0,0 +1,178 @@
+package ledge
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func newFieldValue(value interface{}) *FieldValue {
+	switch value := value.(type) {
+	case *FieldValue:
+		return value
+	case string:
+		return &FieldValue{Type: FieldType_STRING, StringValue: value}
+	case bool:
+		return &FieldValue{Type: FieldType_BOOL, BoolValue: value}
+	case int:
+		return &FieldValue{Type: FieldType_INT, IntValue: int64(value)}
+	case int8:
+		return &FieldValue{Type: FieldType_INT, IntValue: int64(value)}
+	case int16:
+		return &FieldValue{Type: FieldType_INT, IntValue: int64(value)}
+	case int32:
+		return &FieldValue{Type: FieldType_INT, IntValue: int64(value)}
+	case int64:
+		return &FieldValue{Type: FieldType_INT, IntValue: value}
+	case uint:
+		return newUintFieldValue(uint64(value))
+	case uint8:
+		return newUintFieldValue(uint64(value))
+	case uint16:
+		return newUintFieldValue(uint64(value))
+	case uint32:
+		return newUintFieldValue(uint64(value))
+	case uint64:
+		return newUintFieldValue(value)
+	case float32:
+		return &FieldValue{Type: FieldType_FLOAT, FloatValue: float64(value)}
+	case float64:
+		return &FieldValue{Type: FieldType_FLOAT, FloatValue: value}
+	case []byte:
+		return &FieldValue{Type: FieldType_BYTES, BytesValue: value}
+	case time.Time:
+		return &FieldValue{Type: FieldType_TIME, IntValue: value.UnixNano()}
+	case time.Duration:
+		return &FieldValue{Type: FieldType_DURATION, IntValue: int64(value)}
+	case error:
+		return &FieldValue{Type: FieldType_STRING, StringValue: value.Error()}
+	default:
+		return &FieldValue{Type: FieldType_STRING, StringValue: fmt.Sprint(value)}
+	}
+}
+
+// nonFiniteFloatString returns NaN, +Inf or -Inf for floats that JSON can not represent.
+func nonFiniteFloatString(f float64) (string, bool) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'g', -1, 64), true
+	}
+	return "", false
+}
+
+// unsigned integers that do not fit in an int64 are stored as floats
+func newUintFieldValue(value uint64) *FieldValue {
+	if value > math.MaxInt64 {
+		return &FieldValue{Type: FieldType_FLOAT, FloatValue: float64(value)}
+	}
+	return &FieldValue{Type: FieldType_INT, IntValue: int64(value)}
+}
+
+// Value returns the value as a string, int64, float64, bool, []byte, time.Time or time.Duration.
+func (m *FieldValue) Value() interface{} {
+	switch m.GetType() {
+	case FieldType_INT:
+		return m.GetIntValue()
+	case FieldType_FLOAT:
+		return m.GetFloatValue()
+	case FieldType_BOOL:
+		return m.GetBoolValue()
+	case FieldType_BYTES:
+		return m.GetBytesValue()
+	case FieldType_TIME:
+		return time.Unix(0, m.GetIntValue()).UTC()
+	case FieldType_DURATION:
+		return time.Duration(m.GetIntValue())
+	default:
+		return m.GetStringValue()
+	}
+}
+
+// MarshalJSON marshals the value natively. Bytes are marshalled as base64, times as RFC 3339,
+// durations as time.Duration strings and floats that are not finite as NaN, +Inf or -Inf, so
+// these are unmarshalled as strings.
+func (m *FieldValue) MarshalJSON() ([]byte, error) {
+	switch m.GetType() {
+	case FieldType_FLOAT:
+		if s, ok := nonFiniteFloatString(m.GetFloatValue()); ok {
+			return json.Marshal(s)
+		}
+		return json.Marshal(m.GetFloatValue())
+	case FieldType_TIME:
+		return json.Marshal(time.Unix(0, m.GetIntValue()).UTC().Format(time.RFC3339Nano))
+	case FieldType_DURATION:
+		return json.Marshal(time.Duration(m.GetIntValue()).String())
+	default:
+		return json.Marshal(m.Value())
+	}
+}
+
+// UnmarshalJSON unmarshals whole numbers as INT values and other numbers as FLOAT values.
+func (m *FieldValue) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	switch value := value.(type) {
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			*m = FieldValue{Type: FieldType_INT, IntValue: i}
+			return nil
+		}
+		f, err := value.Float64()
+		if err != nil {
+			return err
+		}
+		*m = FieldValue{Type: FieldType_FLOAT, FloatValue: f}
+	case string, bool:
+		*m = *newFieldValue(value)
+	case nil:
+		*m = FieldValue{}
+	default:
+		return fmt.Errorf("ledge: cannot unmarshal %s into a FieldValue", string(data))
+	}
+	return nil
+}
+
+func fieldValueString(fieldValue *FieldValue) string {
+	switch fieldValue.GetType() {
+	case FieldType_STRING:
+		s := fieldValue.GetStringValue()
+		if s == "" || strings.ContainsAny(s, " =\"") {
+			return strconv.Quote(s)
+		}
+		return s
+	case FieldType_BYTES:
+		return base64.StdEncoding.EncodeToString(fieldValue.GetBytesValue())
+	case FieldType_TIME:
+		return time.Unix(0, fieldValue.GetIntValue()).UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(fieldValue.Value())
+	}
+}
+
+// unstructuredEventString appends the fields sorted by key as key=value to the
+// text output of the UnstructuredEvent without fields.
+func unstructuredEventString(unstructuredEvent *UnstructuredEvent) string {
+	s := trimRightSpace((&UnstructuredEvent{Msg: unstructuredEvent.Msg}).String())
+	for _, key := range sortedFieldKeys(unstructuredEvent.Fields) {
+		s = fmt.Sprintf("%s %s=%s", s, key, fieldValueString(unstructuredEvent.Fields[key]))
+	}
+	return strings.TrimLeft(s, " ")
+}
+
+func sortedFieldKeys(fieldValues map[string]*FieldValue) []string {
+	keys := make([]string, 0, len(fieldValues))
+	for key := range fieldValues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,762 @@
+package ledge
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	filterContextPrefix = "ctx."
+	filterEventPrefix   = "event."
+	filterFieldPrefix   = "field."
+)
+
+var (
+	filterTimeLayouts = []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05",
+		"2006-01-02",
+	}
+	levelReflectType    = reflect.TypeOf(Level_NONE)
+	durationReflectType = reflect.TypeOf(time.Duration(0))
+	timeReflectType     = reflect.TypeOf(time.Time{})
+	// fieldValueReflectTypes are the types returned by FieldValue.Value, with strings first
+	fieldValueReflectTypes = []reflect.Type{
+		reflect.TypeOf(""),
+		reflect.TypeOf(int64(0)),
+		reflect.TypeOf(float64(0)),
+		reflect.TypeOf(false),
+		reflect.TypeOf([]byte(nil)),
+		timeReflectType,
+		durationReflectType,
+	}
+)
+
+type filterTokenType int
+
+const (
+	filterTokenEOF filterTokenType = iota
+	filterTokenWord
+	filterTokenString
+	filterTokenComparison
+	filterTokenAnd
+	filterTokenOr
+	filterTokenNot
+	filterTokenLeftParen
+	filterTokenRightParen
+)
+
+type filterToken struct {
+	tokenType filterTokenType
+	value     string
+	position  int
+}
+
+func (f *filterToken) String() string {
+	if f.tokenType == filterTokenEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(f.value)
+}
+
+type filterParser struct {
+	reflectTypeProvider *reflectTypeProvider
+	tokens              []*filterToken
+	index               int
+}
+
+func parseFilter(specification *Specification, expr string) (Filter, error) {
+	reflectTypeProvider, err := newReflectTypeProvider(specification)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := lexFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	parser := &filterParser{
+		reflectTypeProvider,
+		tokens,
+		0,
+	}
+	filter, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := parser.peek(); token.tokenType != filterTokenEOF {
+		return nil, newFilterParseError(token.position, "unexpected %s", token)
+	}
+	return filter, nil
+}
+
+func lexFilter(expr string) ([]*filterToken, error) {
+	var tokens []*filterToken
+	i := 0
+	for i < len(expr) {
+		c := expr[i]
+		r, size := utf8.DecodeRuneInString(expr[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case strings.HasPrefix(expr[i:], "&&"):
+			tokens = append(tokens, &filterToken{filterTokenAnd, "&&", i})
+			i += 2
+		case strings.HasPrefix(expr[i:], "||"):
+			tokens = append(tokens, &filterToken{filterTokenOr, "||", i})
+			i += 2
+		case c == '(':
+			tokens = append(tokens, &filterToken{filterTokenLeftParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, &filterToken{filterTokenRightParen, ")", i})
+			i++
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(expr) && expr[end] != c {
+				if expr[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(expr) {
+				return nil, newFilterParseError(i, "unterminated string")
+			}
+			quoted := expr[i : end+1]
+			if c == '\'' {
+				quoted = "\"" + strings.Replace(quoted[1:len(quoted)-1], "\"", "\\\"", -1) + "\""
+			}
+			value, err := strconv.Unquote(quoted)
+			if err != nil {
+				return nil, newFilterParseError(i, "invalid string %s", expr[i:end+1])
+			}
+			tokens = append(tokens, &filterToken{filterTokenString, value, i})
+			i = end + 1
+		case c == '=' || c == '!' || c == '<' || c == '>':
+			op := string(c)
+			if i+1 < len(expr) && (expr[i+1] == '=' || (expr[i+1] == '~' && (c == '=' || c == '!'))) {
+				op = expr[i : i+2]
+			}
+			if op == "!" {
+				tokens = append(tokens, &filterToken{filterTokenNot, op, i})
+			} else {
+				tokens = append(tokens, &filterToken{filterTokenComparison, op, i})
+			}
+			i += len(op)
+		case c == '&' || c == '|':
+			return nil, newFilterParseError(i, "unexpected %q, did you mean %q", string(c), string(c)+string(c))
+		default:
+			end := i
+			for end < len(expr) {
+				r, size := utf8.DecodeRuneInString(expr[end:])
+				if !isFilterWordRune(r) {
+					break
+				}
+				end += size
+			}
+			tokens = append(tokens, &filterToken{filterTokenWord, expr[i:end], i})
+			i = end
+		}
+	}
+	return append(tokens, &filterToken{filterTokenEOF, "", len(expr)}), nil
+}
+
+func isFilterWordRune(r rune) bool {
+	return !unicode.IsSpace(r) && !strings.ContainsRune("()!=<>&|\"'", r)
+}
+
+func (p *filterParser) peek() *filterToken {
+	return p.tokens[p.index]
+}
+
+func (p *filterParser) next() *filterToken {
+	token := p.tokens[p.index]
+	if token.tokenType != filterTokenEOF {
+		p.index++
+	}
+	return token
+}
+
+func (p *filterParser) parseOr() (Filter, error) {
+	filter, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	filters := []Filter{filter}
+	for p.peek().tokenType == filterTokenOr {
+		p.next()
+		filter, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+	if len(filters) == 1 {
+		return filters[0], nil
+	}
+	return OrFilter(filters...), nil
+}
+
+func (p *filterParser) parseAnd() (Filter, error) {
+	filter, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	filters := []Filter{filter}
+	for p.peek().tokenType == filterTokenAnd {
+		p.next()
+		filter, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+	if len(filters) == 1 {
+		return filters[0], nil
+	}
+	return AndFilter(filters...), nil
+}
+
+func (p *filterParser) parseUnary() (Filter, error) {
+	token := p.peek()
+	switch token.tokenType {
+	case filterTokenNot:
+		p.next()
+		filter, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return NotFilter(filter), nil
+	case filterTokenLeftParen:
+		p.next()
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.tokenType != filterTokenRightParen {
+			return nil, newFilterParseError(closing.position, "expected \")\", got %s", closing)
+		}
+		return filter, nil
+	case filterTokenWord:
+		return p.parseTerm()
+	default:
+		return nil, newFilterParseError(token.position, "expected field, got %s", token)
+	}
+}
+
+func (p *filterParser) parseTerm() (Filter, error) {
+	nameToken := p.next()
+	name := nameToken.value
+	if p.peek().tokenType != filterTokenComparison {
+		if strings.HasPrefix(name, filterContextPrefix) && !strings.Contains(name[len(filterContextPrefix):], ".") {
+			reflectType, err := p.getContextReflectType(nameToken)
+			if err != nil {
+				return nil, err
+			}
+			return FilterFunc(func(entry *Entry) bool {
+				return getContextValue(entry, reflectType).IsValid()
+			}), nil
+		}
+		if strings.HasPrefix(name, filterFieldPrefix) {
+			key := name[len(filterFieldPrefix):]
+			return FilterFunc(func(entry *Entry) bool {
+				return getUnstructuredFieldValue(entry, key) != nil
+			}), nil
+		}
+		return nil, newFilterParseError(p.peek().position, "expected comparison after %s, got %s", name, p.peek())
+	}
+	opToken := p.next()
+	valueToken := p.next()
+	if valueToken.tokenType != filterTokenWord && valueToken.tokenType != filterTokenString {
+		return nil, newFilterParseError(valueToken.position, "expected value, got %s", valueToken)
+	}
+	switch {
+	case name == "level":
+		return p.parseLevelTerm(opToken, valueToken)
+	case name == "event":
+		return p.parseEventTerm(opToken, valueToken)
+	case name == "id":
+		return p.parseStringTerm(opToken, valueToken, func(entry *Entry) string { return entry.ID })
+	case name == "output":
+		return p.parseStringTerm(opToken, valueToken, func(entry *Entry) string { return string(entry.WriterOutput) })
+	case name == "time":
+		return p.parseTimeTerm(opToken, valueToken)
+	case strings.HasPrefix(name, filterContextPrefix):
+		return p.parseContextTerm(nameToken, opToken, valueToken)
+	case strings.HasPrefix(name, filterEventPrefix):
+		return p.parseEventFieldTerm(nameToken, opToken, valueToken)
+	case strings.HasPrefix(name, filterFieldPrefix):
+		return p.parseUnstructuredFieldTerm(nameToken, opToken, valueToken)
+	default:
+		return nil, newFilterParseError(nameToken.position, "unknown field %s", name)
+	}
+}
+
+func (p *filterParser) parseLevelTerm(opToken *filterToken, valueToken *filterToken) (Filter, error) {
+	level, err := parseFilterLevel(valueToken)
+	if err != nil {
+		return nil, err
+	}
+	compare, err := getOrderedCompare(opToken)
+	if err != nil {
+		return nil, err
+	}
+	return FilterFunc(func(entry *Entry) bool {
+		return compare(int(entry.Level) - int(level))
+	}), nil
+}
+
+func (p *filterParser) parseEventTerm(opToken *filterToken, valueToken *filterToken) (Filter, error) {
+	reflectType, err := p.reflectTypeProvider.getEventReflectTypeForShortKey(valueToken.value)
+	if err != nil {
+		if reflectType, err = p.reflectTypeProvider.getEventReflectType(valueToken.value); err != nil {
+			return nil, newFilterParseError(valueToken.position, "unknown event type %s", valueToken.value)
+		}
+	}
+	equal, err := getEqualityCompare(opToken)
+	if err != nil {
+		return nil, err
+	}
+	return FilterFunc(func(entry *Entry) bool {
+		return equal(reflect.TypeOf(entry.Event) == reflectType)
+	}), nil
+}
+
+func (p *filterParser) parseStringTerm(opToken *filterToken, valueToken *filterToken, getString func(*Entry) string) (Filter, error) {
+	compare, err := getStringCompare(opToken, valueToken)
+	if err != nil {
+		return nil, err
+	}
+	return FilterFunc(func(entry *Entry) bool {
+		return compare(getString(entry))
+	}), nil
+}
+
+func (p *filterParser) parseTimeTerm(opToken *filterToken, valueToken *filterToken) (Filter, error) {
+	t, err := parseFilterTime(valueToken)
+	if err != nil {
+		return nil, err
+	}
+	compare, err := getOrderedCompare(opToken)
+	if err != nil {
+		return nil, err
+	}
+	return FilterFunc(func(entry *Entry) bool {
+		return compare(compareTime(entry.Time, t))
+	}), nil
+}
+
+func (p *filterParser) parseContextTerm(nameToken *filterToken, opToken *filterToken, valueToken *filterToken) (Filter, error) {
+	reflectType, err := p.getContextReflectType(nameToken)
+	if err != nil {
+		return nil, err
+	}
+	fieldPath := strings.Split(nameToken.value[len(filterContextPrefix):], ".")[1:]
+	fieldType, err := getFieldType(reflectType, fieldPath)
+	if err != nil {
+		return nil, newFilterParseError(nameToken.position, "%s", err.Error())
+	}
+	compare, err := getValueCompare(fieldType, opToken, valueToken)
+	if err != nil {
+		return nil, err
+	}
+	return FilterFunc(func(entry *Entry) bool {
+		value := getFieldValue(getContextValue(entry, reflectType), fieldPath)
+		return value.IsValid() && compare(value)
+	}), nil
+}
+
+func (p *filterParser) parseEventFieldTerm(nameToken *filterToken, opToken *filterToken, valueToken *filterToken) (Filter, error) {
+	fieldPath := strings.Split(nameToken.value[len(filterEventPrefix):], ".")
+	// the Event type is not known until an Entry is seen, so the value is checked against
+	// the field of every Event type in the Specification that has it, and is an error only
+	// if it is invalid for all of them
+	fieldTypes := make(map[reflect.Type]bool)
+	for eventReflectType := range p.reflectTypeProvider.eventReflectTypes {
+		if fieldType, err := getFieldType(eventReflectType, fieldPath); err == nil {
+			fieldTypes[fieldType] = true
+		}
+	}
+	if len(fieldTypes) == 0 {
+		return nil, newFilterParseError(nameToken.position, "no field %s in any event type", strings.Join(fieldPath, "."))
+	}
+	compares, err := getValueCompares(sortedReflectTypes(fieldTypes), opToken, valueToken)
+	if err != nil {
+		return nil, err
+	}
+	lock := &sync.Mutex{}
+	return FilterFunc(func(entry *Entry) bool {
+		value := getFieldValue(reflect.ValueOf(entry.Event), fieldPath)
+		if !value.IsValid() {
+			return false
+		}
+		lock.Lock()
+		compare, ok := compares[value.Type()]
+		if !ok {
+			// fields of interface types only have their type once an Entry is seen
+			compare, _ = getValueCompare(value.Type(), opToken, valueToken)
+			compares[value.Type()] = compare
+		}
+		lock.Unlock()
+		return compare != nil && compare(value)
+	}), nil
+}
+
+func (p *filterParser) parseUnstructuredFieldTerm(nameToken *filterToken, opToken *filterToken, valueToken *filterToken) (Filter, error) {
+	key := nameToken.value[len(filterFieldPrefix):]
+	// as with Event fields, the FieldType is not known until an Entry is seen, so the value
+	// is an error only if it is invalid for every FieldType
+	compares, err := getValueCompares(fieldValueReflectTypes, opToken, valueToken)
+	if err != nil {
+		return nil, err
+	}
+	return FilterFunc(func(entry *Entry) bool {
+		fieldValue := getUnstructuredFieldValue(entry, key)
+		if fieldValue == nil {
+			return false
+		}
+		value := reflect.ValueOf(fieldValue.Value())
+		compare := compares[value.Type()]
+		return compare != nil && compare(value)
+	}), nil
+}
+
+func (p *filterParser) getContextReflectType(nameToken *filterToken) (reflect.Type, error) {
+	typeName := strings.Split(nameToken.value[len(filterContextPrefix):], ".")[0]
+	reflectType, err := p.reflectTypeProvider.getContextReflectTypeForShortKey(typeName)
+	if err != nil {
+		return nil, newFilterParseError(nameToken.position+len(filterContextPrefix), "unknown context type %s", typeName)
+	}
+	return reflectType, nil
+}
+
+func getContextValue(entry *Entry, reflectType reflect.Type) reflect.Value {
+	for _, context := range entry.Contexts {
+		if reflect.TypeOf(context) == reflectType {
+			return reflect.ValueOf(context)
+		}
+	}
+	return reflect.Value{}
+}
+
+func getUnstructuredFieldValue(entry *Entry, key string) *FieldValue {
+	if unstructuredEvent, ok := entry.Event.(*UnstructuredEvent); ok {
+		return unstructuredEvent.Fields[key]
+	}
+	return nil
+}
+
+func getFieldValue(value reflect.Value, fieldPath []string) reflect.Value {
+	for _, fieldName := range fieldPath {
+		for value.IsValid() && (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) {
+			if value.IsNil() {
+				return reflect.Value{}
+			}
+			value = value.Elem()
+		}
+		if !value.IsValid() || value.Kind() != reflect.Struct {
+			return reflect.Value{}
+		}
+		value = value.FieldByName(fieldName)
+		// fields of interface types may resolve to unexported fields once an Entry is seen
+		if value.IsValid() && !value.CanInterface() {
+			return reflect.Value{}
+		}
+	}
+	return value
+}
+
+// getFieldType returns the type of the field at fieldPath in reflectType, dereferencing pointers.
+// Unexported fields are an error, as their values can not be compared.
+func getFieldType(reflectType reflect.Type, fieldPath []string) (reflect.Type, error) {
+	fieldType := reflectType
+	for _, fieldName := range fieldPath {
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("no field %s in %v", fieldName, fieldType)
+		}
+		field, ok := fieldType.FieldByName(fieldName)
+		if !ok {
+			return nil, fmt.Errorf("no field %s in %v", fieldName, fieldType)
+		}
+		if field.PkgPath != "" {
+			return nil, fmt.Errorf("field %s in %v is unexported", fieldName, fieldType)
+		}
+		fieldType = field.Type
+	}
+	return fieldType, nil
+}
+
+// getValueCompares returns the compare functions for every type the value is valid for,
+// or the error for the first type if it is valid for none.
+func getValueCompares(reflectTypes []reflect.Type, opToken *filterToken, valueToken *filterToken) (map[reflect.Type]func(reflect.Value) bool, error) {
+	compares := make(map[reflect.Type]func(reflect.Value) bool)
+	var firstErr error
+	for _, reflectType := range reflectTypes {
+		compare, err := getValueCompare(reflectType, opToken, valueToken)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		compares[reflectType] = compare
+	}
+	if len(compares) == 0 {
+		return nil, firstErr
+	}
+	return compares, nil
+}
+
+func sortedReflectTypes(reflectTypes map[reflect.Type]bool) []reflect.Type {
+	sorted := make([]reflect.Type, 0, len(reflectTypes))
+	for reflectType := range reflectTypes {
+		sorted = append(sorted, reflectType)
+	}
+	sort.Slice(sorted, func(i int, j int) bool { return sorted[i].String() < sorted[j].String() })
+	return sorted
+}
+
+func getValueCompare(reflectType reflect.Type, opToken *filterToken, valueToken *filterToken) (func(reflect.Value) bool, error) {
+	if reflectType == levelReflectType {
+		level, err := parseFilterLevel(valueToken)
+		if err != nil {
+			return nil, err
+		}
+		compare, err := getOrderedCompare(opToken)
+		if err != nil {
+			return nil, err
+		}
+		return func(value reflect.Value) bool {
+			return compare(int(value.Int()) - int(level))
+		}, nil
+	}
+	if reflectType == durationReflectType {
+		d, err := time.ParseDuration(valueToken.value)
+		if err != nil {
+			return nil, newFilterParseError(valueToken.position, "invalid duration %s", valueToken.value)
+		}
+		compare, err := getOrderedCompare(opToken)
+		if err != nil {
+			return nil, err
+		}
+		return func(value reflect.Value) bool {
+			return compare(compareInt64(value.Int(), int64(d)))
+		}, nil
+	}
+	if reflectType == timeReflectType {
+		t, err := parseFilterTime(valueToken)
+		if err != nil {
+			return nil, err
+		}
+		compare, err := getOrderedCompare(opToken)
+		if err != nil {
+			return nil, err
+		}
+		return func(value reflect.Value) bool {
+			return compare(compareTime(value.Interface().(time.Time), t))
+		}, nil
+	}
+	switch reflectType.Kind() {
+	case reflect.String:
+		compare, err := getStringCompare(opToken, valueToken)
+		if err != nil {
+			return nil, err
+		}
+		return func(value reflect.Value) bool {
+			return compare(value.String())
+		}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(valueToken.value, 10, 64)
+		if err != nil {
+			return nil, newFilterParseError(valueToken.position, "invalid integer %s", valueToken.value)
+		}
+		compare, err := getOrderedCompare(opToken)
+		if err != nil {
+			return nil, err
+		}
+		return func(value reflect.Value) bool {
+			return compare(compareInt64(value.Int(), i))
+		}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(valueToken.value, 10, 64)
+		if err != nil {
+			return nil, newFilterParseError(valueToken.position, "invalid unsigned integer %s", valueToken.value)
+		}
+		compare, err := getOrderedCompare(opToken)
+		if err != nil {
+			return nil, err
+		}
+		return func(value reflect.Value) bool {
+			return compare(compareUint64(value.Uint(), u))
+		}, nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(valueToken.value, 64)
+		if err != nil {
+			return nil, newFilterParseError(valueToken.position, "invalid number %s", valueToken.value)
+		}
+		compare, err := getOrderedCompare(opToken)
+		if err != nil {
+			return nil, err
+		}
+		return func(value reflect.Value) bool {
+			return compare(compareFloat64(value.Float(), f))
+		}, nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(valueToken.value)
+		if err != nil {
+			return nil, newFilterParseError(valueToken.position, "invalid bool %s", valueToken.value)
+		}
+		equal, err := getEqualityCompare(opToken)
+		if err != nil {
+			return nil, err
+		}
+		return func(value reflect.Value) bool {
+			return equal(value.Bool() == b)
+		}, nil
+	case reflect.Slice:
+		if reflectType.Elem().Kind() != reflect.Uint8 {
+			break
+		}
+		compare, err := getStringCompare(opToken, valueToken)
+		if err != nil {
+			return nil, err
+		}
+		return func(value reflect.Value) bool {
+			return compare(string(value.Bytes()))
+		}, nil
+	}
+	compare, err := getStringCompare(opToken, valueToken)
+	if err != nil {
+		return nil, err
+	}
+	return func(value reflect.Value) bool {
+		return compare(fmt.Sprint(value.Interface()))
+	}, nil
+}
+
+func getOrderedCompare(opToken *filterToken) (func(int) bool, error) {
+	switch opToken.value {
+	case "=", "==":
+		return func(c int) bool { return c == 0 }, nil
+	case "!=":
+		return func(c int) bool { return c != 0 }, nil
+	case "<":
+		return func(c int) bool { return c < 0 }, nil
+	case "<=":
+		return func(c int) bool { return c <= 0 }, nil
+	case ">":
+		return func(c int) bool { return c > 0 }, nil
+	case ">=":
+		return func(c int) bool { return c >= 0 }, nil
+	default:
+		return nil, newFilterParseError(opToken.position, "operator %s not supported here", opToken.value)
+	}
+}
+
+func getEqualityCompare(opToken *filterToken) (func(bool) bool, error) {
+	switch opToken.value {
+	case "=", "==":
+		return func(equal bool) bool { return equal }, nil
+	case "!=":
+		return func(equal bool) bool { return !equal }, nil
+	default:
+		return nil, newFilterParseError(opToken.position, "operator %s not supported here, use == or !=", opToken.value)
+	}
+}
+
+func getStringCompare(opToken *filterToken, valueToken *filterToken) (func(string) bool, error) {
+	switch opToken.value {
+	case "=~", "!~":
+		r, err := regexp.Compile(valueToken.value)
+		if err != nil {
+			return nil, newFilterParseError(valueToken.position, "invalid regular expression %s: %s", valueToken.value, err.Error())
+		}
+		match := opToken.value == "=~"
+		return func(s string) bool { return r.MatchString(s) == match }, nil
+	default:
+		compare, err := getOrderedCompare(opToken)
+		if err != nil {
+			return nil, err
+		}
+		return func(s string) bool { return compare(strings.Compare(s, valueToken.value)) }, nil
+	}
+}
+
+func parseFilterTime(valueToken *filterToken) (time.Time, error) {
+	for _, layout := range filterTimeLayouts {
+		if t, err := time.ParseInLocation(layout, valueToken.value, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, newFilterParseError(valueToken.position, "invalid time %s", valueToken.value)
+}
+
+func parseFilterLevel(valueToken *filterToken) (Level, error) {
+	level, ok := Level_value[strings.ToUpper(valueToken.value)]
+	if !ok {
+		return Level_NONE, newFilterParseError(valueToken.position, "unknown level %s", valueToken.value)
+	}
+	return Level(level), nil
+}
+
+func compareInt64(i int64, j int64) int {
+	switch {
+	case i < j:
+		return -1
+	case i > j:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareTime(t time.Time, u time.Time) int {
+	switch {
+	case t.Before(u):
+		return -1
+	case t.After(u):
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareUint64(i uint64, j uint64) int {
+	switch {
+	case i < j:
+		return -1
+	case i > j:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareFloat64(i float64, j float64) int {
+	switch {
+	case i < j:
+		return -1
+	case i > j:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func newFilterParseError(position int, format string, args ...interface{}) *FilterParseError {
+	return &FilterParseError{
+		Position: position,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
@@ -1,5 +1,13 @@
 package ledge
 
+import (
+	"reflect"
+	"regexp"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+)
+
 type requireContextFilter struct {
 	context Context
 }
@@ -36,3 +44,174 @@ func newLevelFilter(
 func (l *levelFilter) Include(entry *Entry) bool {
 	return l.level <= entry.Level
 }
+
+type andFilter struct {
+	filters []Filter
+}
+
+func newAndFilter(
+	filters []Filter,
+) *andFilter {
+	return &andFilter{
+		filters,
+	}
+}
+
+func (a *andFilter) Include(entry *Entry) bool {
+	return includeEntry(a.filters, entry)
+}
+
+type orFilter struct {
+	filters []Filter
+}
+
+func newOrFilter(
+	filters []Filter,
+) *orFilter {
+	return &orFilter{
+		filters,
+	}
+}
+
+func (o *orFilter) Include(entry *Entry) bool {
+	for _, filter := range o.filters {
+		if filter.Include(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+type notFilter struct {
+	filter Filter
+}
+
+func newNotFilter(
+	filter Filter,
+) *notFilter {
+	return &notFilter{
+		filter,
+	}
+}
+
+func (n *notFilter) Include(entry *Entry) bool {
+	return !n.filter.Include(entry)
+}
+
+type eventTypeFilter struct {
+	reflectType reflect.Type
+}
+
+func newEventTypeFilter(
+	event Event,
+) *eventTypeFilter {
+	return &eventTypeFilter{
+		reflect.TypeOf(event),
+	}
+}
+
+func (e *eventTypeFilter) Include(entry *Entry) bool {
+	return reflect.TypeOf(entry.Event) == e.reflectType
+}
+
+type contextTypesFilter struct {
+	reflectTypes map[reflect.Type]bool
+}
+
+func newContextTypesFilter(
+	contexts []Context,
+) *contextTypesFilter {
+	reflectTypes := make(map[reflect.Type]bool)
+	for _, context := range contexts {
+		reflectTypes[reflect.TypeOf(context)] = true
+	}
+	return &contextTypesFilter{
+		reflectTypes,
+	}
+}
+
+func (c *contextTypesFilter) Include(entry *Entry) bool {
+	for _, context := range entry.Contexts {
+		if c.reflectTypes[reflect.TypeOf(context)] {
+			return true
+		}
+	}
+	return false
+}
+
+type timeRangeFilter struct {
+	start time.Time
+	end   time.Time
+}
+
+func newTimeRangeFilter(
+	start time.Time,
+	end time.Time,
+) *timeRangeFilter {
+	return &timeRangeFilter{
+		start,
+		end,
+	}
+}
+
+func (t *timeRangeFilter) Include(entry *Entry) bool {
+	if !t.start.IsZero() && entry.Time.Before(t.start) {
+		return false
+	}
+	if !t.end.IsZero() && !entry.Time.Before(t.end) {
+		return false
+	}
+	return true
+}
+
+type idFilter struct {
+	id string
+}
+
+func newIDFilter(
+	id string,
+) *idFilter {
+	return &idFilter{
+		id,
+	}
+}
+
+func (i *idFilter) Include(entry *Entry) bool {
+	return entry.ID == i.id
+}
+
+type writerOutputFilter struct {
+	regexp *regexp.Regexp
+}
+
+func newWriterOutputFilter(
+	regexp *regexp.Regexp,
+) *writerOutputFilter {
+	return &writerOutputFilter{
+		regexp,
+	}
+}
+
+func (w *writerOutputFilter) Include(entry *Entry) bool {
+	return w.regexp.Match(entry.WriterOutput)
+}
+
+type fieldFilter struct {
+	key        string
+	fieldValue *FieldValue
+}
+
+func newFieldFilter(
+	key string,
+	value interface{},
+) *fieldFilter {
+	return &fieldFilter{
+		key,
+		newFieldValue(value),
+	}
+}
+
+func (f *fieldFilter) Include(entry *Entry) bool {
+	fieldValue := getUnstructuredFieldValue(entry, f.key)
+	return fieldValue != nil && proto.Equal(fieldValue, f.fieldValue)
+}
@@ -0,0 +1,146 @@
+package ledge
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// followReader is an io.Reader that waits for more data at the end of the
+// underlying io.Reader instead of returning io.EOF, until cancelled.
+type followReader struct {
+	reader io.Reader
+	// file is set if the underlying io.Reader is an *os.File, so that
+	// truncation and replacement of the file can be detected
+	file *os.File
+	// ownsFile is set once the original file has been replaced by one we opened
+	ownsFile bool
+	// next is the file that replaced file, which is switched to once file is drained
+	next     *os.File
+	offset   int64
+	interval time.Duration
+	cancel   <-chan struct{}
+}
+
+func newFollowReader(
+	reader io.Reader,
+	interval time.Duration,
+	cancel <-chan struct{},
+) *followReader {
+	if interval <= 0 {
+		interval = DefaultFollowInterval
+	}
+	file, _ := reader.(*os.File)
+	var offset int64
+	if file != nil {
+		// the file may already have been read from or seeked, such as to its end
+		offset, _ = file.Seek(0, io.SeekCurrent)
+	}
+	return &followReader{
+		reader,
+		file,
+		false,
+		nil,
+		offset,
+		interval,
+		cancel,
+	}
+}
+
+func (f *followReader) Read(p []byte) (int, error) {
+	for {
+		n, err := f.reader.Read(p)
+		f.offset += int64(n)
+		if n > 0 {
+			return n, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		if f.next != nil {
+			if err := f.switchToNext(); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		if f.file != nil {
+			changed, err := f.checkFile()
+			if err != nil {
+				return 0, err
+			}
+			if changed {
+				continue
+			}
+		}
+		select {
+		case <-f.cancel:
+			return 0, io.EOF
+		case <-time.After(f.interval):
+		}
+	}
+}
+
+func (f *followReader) Close() error {
+	var err error
+	if f.ownsFile && f.file != nil {
+		err = f.file.Close()
+	}
+	if f.next != nil {
+		if nextErr := f.next.Close(); nextErr != nil && err == nil {
+			err = nextErr
+		}
+	}
+	f.file = nil
+	f.next = nil
+	return err
+}
+
+// checkFile returns true if the file was truncated or replaced.
+func (f *followReader) checkFile() (bool, error) {
+	pathFileInfo, err := os.Stat(f.file.Name())
+	if err != nil {
+		// the file was moved away and a new one has not been created yet
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	fileInfo, err := f.file.Stat()
+	if err != nil {
+		return false, err
+	}
+	if !os.SameFile(pathFileInfo, fileInfo) {
+		next, err := os.Open(f.file.Name())
+		if err != nil {
+			if os.IsNotExist(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		// read anything written to the old file before it was replaced before switching
+		f.next = next
+		return true, nil
+	}
+	if fileInfo.Size() < f.offset {
+		if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+			return false, err
+		}
+		f.offset = 0
+		return true, nil
+	}
+	return false, nil
+}
+
+func (f *followReader) switchToNext() error {
+	if f.ownsFile {
+		if err := f.file.Close(); err != nil {
+			return err
+		}
+	}
+	f.file = f.next
+	f.reader = f.next
+	f.ownsFile = true
+	f.next = nil
+	f.offset = 0
+	return nil
+}
@@ -0,0 +1,134 @@
+package ledge
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	gelfVersion      = "1.1"
+	gelfFieldsPrefix = "_fields."
+)
+
+var (
+	levelToSyslogSeverity = map[Level]int{
+		Level_DEBUG: 7,
+		Level_INFO:  6,
+		Level_WARN:  4,
+		Level_ERROR: 3,
+		Level_FATAL: 2,
+		Level_PANIC: 1,
+	}
+	gelfInvalidFieldCharacters = regexp.MustCompile(`[^\w.\-]`)
+	gelfReservedFieldKeys      = map[string]bool{
+		"_entry_id":   true,
+		"_event_type": true,
+		"_file":       true,
+		"_line":       true,
+		"_function":   true,
+	}
+)
+
+type gelfMarshaller struct {
+	host string
+}
+
+func newGELFMarshaller(
+	options GELFMarshallerOptions,
+) *gelfMarshaller {
+	host := options.Host
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+	return &gelfMarshaller{
+		host,
+	}
+}
+
+func (g *gelfMarshaller) Marshal(entry *Entry) ([]byte, error) {
+	eventKey, err := textMarshallerObjectKeyString(entry.Event)
+	if err != nil {
+		return nil, err
+	}
+	severity, ok := levelToSyslogSeverity[entry.Level]
+	if !ok {
+		severity = levelToSyslogSeverity[Level_INFO]
+	}
+	shortMessage := getEntryMessage(entry)
+	if shortMessage == "" {
+		// short_message is required to be non-empty
+		shortMessage = eventKey
+	}
+	m := map[string]interface{}{
+		"version":       gelfVersion,
+		"host":          g.host,
+		"short_message": shortMessage,
+		"timestamp":     json.Number(fmt.Sprintf("%d.%03d", entry.Time.Unix(), entry.Time.Nanosecond()/int(1e6))),
+		"level":         severity,
+		"_entry_id":     entry.ID,
+		"_event_type":   eventKey,
+	}
+	if stack := getEntryStack(entry); stack != "" {
+		m["full_message"] = stack
+	}
+	addField := func(key string, value interface{}) {
+		m[getGELFFieldKey(key)] = getGELFFieldValue(value)
+	}
+	for _, context := range entry.Contexts {
+		contextKey, err := textMarshallerObjectKeyString(context)
+		if err != nil {
+			return nil, err
+		}
+		if err := flattenJSONObject(contextKey, context, "_", addField); err != nil {
+			return nil, err
+		}
+	}
+	if unstructuredEvent, ok := entry.Event.(*UnstructuredEvent); ok {
+		for key, fieldValue := range unstructuredEvent.Fields {
+			addField(key, getFieldValueJSONValue(fieldValue))
+		}
+	} else if err := flattenJSONObject(eventKey, entry.Event, "_", addField); err != nil {
+		return nil, err
+	}
+	if entry.Caller != nil {
+		m["_file"] = entry.Caller.File
+		m["_line"] = entry.Caller.Line
+		m["_function"] = entry.Caller.Function
+	}
+	return json.Marshal(m)
+}
+
+// getGELFFieldKey returns the key prefixed with _, with characters not allowed by GELF replaced
+// with _. The reserved key _id is not allowed and is returned as __id, and keys used by the Entry
+// itself are prefixed with _fields. so that they do not overwrite its values.
+func getGELFFieldKey(key string) string {
+	key = "_" + gelfInvalidFieldCharacters.ReplaceAllString(key, "_")
+	if key == "_id" {
+		return "__id"
+	}
+	if gelfReservedFieldKeys[key] || strings.HasPrefix(key, gelfFieldsPrefix) {
+		return gelfFieldsPrefix + key[1:]
+	}
+	return key
+}
+
+// getGELFFieldValue returns strings and numbers as is, as GELF only allows these for
+// additional fields, and anything else as a string.
+func getGELFFieldValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case string, json.Number, int64, float64:
+		return value
+	case bool:
+		return strconv.FormatBool(value)
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(data)
+	}
+}
@@ -0,0 +1,49 @@
+package ledge
+
+import (
+	"context"
+)
+
+type loggerContextKey struct{}
+
+type contextsContextKey struct{}
+
+func newContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, logger)
+}
+
+func fromContext(ctx context.Context) Logger {
+	logger, ok := ctx.Value(loggerContextKey{}).(Logger)
+	if !ok {
+		globalLock.Lock()
+		logger = globalLogger
+		globalLock.Unlock()
+	}
+	if logger == nil {
+		return nil
+	}
+	return withContextsFromContext(logger, ctx)
+}
+
+func appendContexts(ctx context.Context, contexts []Context) context.Context {
+	if len(contexts) == 0 {
+		return ctx
+	}
+	existingContexts := contextsFromContext(ctx)
+	// copied so that contexts attached on sibling context.Contexts do not share an array
+	newContexts := make([]Context, 0, len(existingContexts)+len(contexts))
+	newContexts = append(newContexts, existingContexts...)
+	return context.WithValue(ctx, contextsContextKey{}, append(newContexts, contexts...))
+}
+
+func contextsFromContext(ctx context.Context) []Context {
+	contexts, _ := ctx.Value(contextsContextKey{}).([]Context)
+	return contexts
+}
+
+func withContextsFromContext(logger Logger, ctx context.Context) Logger {
+	for _, context := range contextsFromContext(ctx) {
+		logger = logger.WithContext(context)
+	}
+	return logger
+}
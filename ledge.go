@@ -2,14 +2,27 @@ package ledge
 
 import (
 	"bufio"
+	"context"
+	"fmt"
 	"io"
+	"log"
+	"net/http"
+	"regexp"
 	"sync"
 	"time"
+
+	"github.com/sirupsen/logrus"
 )
 
 const (
 	// DefaultColumns is the default number of columns to use for the V3 text marshaller.
 	DefaultColumns = 100
+	// DefaultAsyncQueueSize is the default number of Entry objects an AsyncLogger will buffer.
+	DefaultAsyncQueueSize = 1024
+	// DefaultFollowInterval is the default interval at which an EntryReader in Follow mode checks for more data.
+	DefaultFollowInterval = 250 * time.Millisecond
+	// DefaultSuppressedReportInterval is the interval at which a SuppressedReporter logs if none is given.
+	DefaultSuppressedReportInterval = time.Minute
 )
 
 var (
@@ -28,23 +41,53 @@ var (
 
 	// JSONMarshaller is a Marshaller that marshales Entries in JSON, with shorthand
 	// notation for Context and Entry types. It should not be used for logging intended for RPC use.
-	JSONMarshaller = newJSONMarshaller(defaultJSONKeys)
+	JSONMarshaller = newJSONMarshaller(JSONMarshallerOptions{})
+	// FullyQualifiedJSONMarshaller is a Marshaller that marshals Entries in JSON, with fully
+	// qualified names for Context and Entry types. Use this if short type names collide.
+	FullyQualifiedJSONMarshaller = newJSONMarshaller(JSONMarshallerOptions{FullyQualifiedTypeNames: true})
+	// ECSMarshaller is a Marshaller that marshals Entries in JSON with the fields of the Elastic
+	// Common Schema, for shipping to Elasticsearch. The time is @timestamp, the Level is log.level,
+	// the Event type name is event.dataset, and the writer output, or the message of an
+	// UnstructuredEvent or ErrorEvent, is message. Contexts and the Fields of an UnstructuredEvent
+	// are flattened into labels, with nested keys joined by _, and any other Event is keyed by its
+	// type name. The Caller is log.origin, and stacks are error.stack_trace.
+	ECSMarshaller = ecsMarshallerInstance
+	// LogfmtMarshaller is a Marshaller that marshals Entries in logfmt, see NewLogfmtMarshaller.
+	LogfmtMarshaller = newLogfmtMarshaller(TextMarshallerOptions{})
 	// ProtoMarshaller is a Marshaller for Protocol Buffers. It is intended for RPC use.
+	// Entries are base64 encoded so that they can be written one per line with RPCEncoder.
 	ProtoMarshaller = protoMarshallerInstance
+	// BinaryProtoMarshaller is a Marshaller for Protocol Buffers that does not base64 encode
+	// Entries, which makes them smaller and faster to marshal. It must be used with
+	// LengthDelimitedEncoder. Entries can be read with NewProtoUnmarshaller.
+	BinaryProtoMarshaller = binaryProtoMarshallerInstance
 	// RPCEncoder is an Encoder that wraps data in a simple RPC format.
 	RPCEncoder = rpcEncoderInstance
-	// RPCDecoder is a Decoder that decodes data encoded with RPCEncoder.
+	// RPCDecoder is a Decoder that decodes data encoded with RPCEncoder. Data encoded with
+	// LengthDelimitedEncoder is detected and decoded as well, so streams can use either or both.
 	RPCDecoder = rpcDecoderInstance
+	// LengthDelimitedEncoder is an Encoder that prefixes data with a zero byte and its length
+	// as a varint, so that data can contain newlines.
+	LengthDelimitedEncoder = lengthDelimitedEncoderInstance
+	// LengthDelimitedDecoder is a Decoder that decodes data encoded with LengthDelimitedEncoder only.
+	LengthDelimitedDecoder = lengthDelimitedDecoderInstance
+	// UUIDAllocator is the IDAllocator used by default, which allocates random UUIDs.
+	UUIDAllocator IDAllocator = uuidAllocatorInstance
 
 	// DefaultEventTypes are the Event types included with every Logger, EntryReader,
-	// and BlockingEntryReader by default. These are used for the UnstructuredLogger.
+	// and BlockingEntryReader by default. These are used for the UnstructuredLogger
+	// and for SuppressedReporter.
 	DefaultEventTypes = []Event{
 		&UnstructuredEvent{},
 		&ErrorEvent{},
+		&SuppressedEvent{},
 	}
 
 	globalLogger Logger
 	globalLock   = &sync.Mutex{}
+
+	registeredSpecifications []*Specification
+	registeredLock           = &sync.Mutex{}
 )
 
 // SetLogger sets the global Logger. This must be called before any global logging calls.
@@ -55,12 +98,74 @@ func SetLogger(logger Logger) {
 	globalLogger = logger
 }
 
+// RegisterSpecification registers a Specification globally. This is intended to be called
+// from init functions, so that tools that read Entry objects, such as the ledge command,
+// know about Context and Event types they were not compiled with.
+func RegisterSpecification(specification *Specification) {
+	registeredLock.Lock()
+	defer registeredLock.Unlock()
+
+	registeredSpecifications = append(registeredSpecifications, specification)
+}
+
+// RegisterAdapterPackage registers the import path of a package that wraps a Logger, such as a
+// bridge from another logging library or middleware, so that the Caller of an Entry is the first
+// function outside of the package. This is intended to be called from init functions.
+func RegisterAdapterPackage(packagePath string) {
+	registerAdapterPackage(packagePath)
+}
+
+// RegisteredSpecification returns all Specifications registered with RegisterSpecification merged into one.
+func RegisteredSpecification() *Specification {
+	registeredLock.Lock()
+	defer registeredLock.Unlock()
+
+	return mergeSpecifications(registeredSpecifications)
+}
+
+// NewContext returns a copy of ctx that carries the given Logger, to be returned by FromContext.
+func NewContext(ctx context.Context, logger Logger) context.Context {
+	return newContext(ctx, logger)
+}
+
+// FromContext returns the Logger carried by ctx, or the global Logger if there is none, with
+// every Context attached to ctx with AppendContexts attached. Contexts are attached whether
+// they were appended before or after the Logger was added with NewContext.
+func FromContext(ctx context.Context) Logger {
+	return fromContext(ctx)
+}
+
+// AppendContexts returns a copy of ctx with the given Contexts attached after any Contexts
+// already attached to ctx, so that they are picked up by FromContext and WithContextsFromContext.
+// This allows Contexts such as request IDs to be attached once, for example by HTTP middleware,
+// without passing a Logger around. The Contexts must be registered in the Specification of every
+// Logger they are picked up by.
+func AppendContexts(ctx context.Context, contexts ...Context) context.Context {
+	return appendContexts(ctx, contexts)
+}
+
+// ContextsFromContext returns the Contexts attached to ctx with AppendContexts, in order.
+func ContextsFromContext(ctx context.Context) []Context {
+	return contextsFromContext(ctx)
+}
+
+// WithContextsFromContext returns the given Logger with every Context attached to ctx with
+// AppendContexts attached, as with Logger.WithContext.
+func WithContextsFromContext(logger Logger, ctx context.Context) Logger {
+	return withContextsFromContext(logger, ctx)
+}
+
 // WithContext returns a new Logger with the given Context attached. If the Context
 // was not registered in the Specification on Logger creation, this method will panic.
 func WithContext(context Context) Logger {
 	return globalLogger.WithContext(context)
 }
 
+// Named returns a new Logger with the given name, as with Logger.Named.
+func Named(name string) Logger {
+	return globalLogger.Named(name)
+}
+
 // Unstructured returns the associated UnstructuredLogger. The methods on UnstructuredLogger
 // are not directly included on Logger to discourage use of these methods.
 func Unstructured() UnstructuredLogger {
@@ -131,9 +236,57 @@ type Context interface{}
 // An Event is outputted by a Logger.
 type Event interface{}
 
-// Fields are attached to an UnstructuredLogger and included as part of every statement outputted.
+// RawFormat is the format of the Data of a RawContext or RawEvent.
+type RawFormat int
+
+const (
+	// RawFormatProto is the format used by ProtoMarshaller, either a marshalled protocol buffer
+	// message or a gob-encoded value.
+	RawFormatProto RawFormat = iota
+	// RawFormatJSON is the format used by JSONMarshaller and FullyQualifiedJSONMarshaller,
+	// and by the logfmt Unmarshaller, which re-assembles the JSON of unknown types.
+	RawFormatJSON
+)
+
+// RawContext is returned in place of a Context by a lenient Unmarshaller when the Context type
+// is not part of the Specification. Marshallers re-emit the raw data unchanged where the format allows.
+type RawContext struct {
+	// TypeName is the type name the Context was marshalled with.
+	TypeName string
+	// Format is the format of Data.
+	Format RawFormat
+	// Data is the marshalled Context.
+	Data []byte
+}
+
+// RawEvent is returned in place of an Event by a lenient Unmarshaller when the Event type
+// is not part of the Specification. Marshallers re-emit the raw data unchanged where the format allows.
+type RawEvent struct {
+	// TypeName is the type name the Event was marshalled with.
+	TypeName string
+	// Format is the format of Data.
+	Format RawFormat
+	// Data is the marshalled Event.
+	Data []byte
+}
+
+// ShortTypeName returns the short name for a fully qualified type name, such as the TypeName of a
+// RawContext or RawEvent, for example Foo for *"github.com/foo/bar".Foo. Short names are returned as is.
+func ShortTypeName(typeName string) string {
+	return shortTypeName(typeName)
+}
+
+// Fields are attached to an UnstructuredLogger and included in the Fields of every UnstructuredEvent
+// outputted. Values are converted with NewFieldValue.
 type Fields map[string]interface{}
 
+// NewFieldValue returns a new FieldValue for the given value. Strings, bools, integers, floats,
+// []byte, time.Time and time.Duration values keep their type, errors are stored as their message,
+// and any other value is stored as formatted by fmt.Sprint.
+func NewFieldValue(value interface{}) *FieldValue {
+	return newFieldValue(value)
+}
+
 // An UnstructuredLogger allows logging without the use of typed Events. This is meant to be used
 // for quick additional logger, for adoption, and as a replacement for Golang's standard logger.
 // In general, if using the idioms of this library, use of UnstructuredLogger is discouraged.
@@ -200,11 +353,38 @@ type UnstructuredLogger interface {
 	WarnWriter() io.Writer
 }
 
+// NewStdLogger returns a new *log.Logger that logs every message as an UnstructuredEvent at the
+// given Level, for libraries that take a *log.Logger. The prefix is added as the "prefix" field,
+// and the file and line as the "file" field if log.Lshortfile or log.Llongfile is set. The date
+// and time are dropped, as every Entry has a Time.
+func NewStdLogger(unstructuredLogger UnstructuredLogger, level Level, prefix string, flags int) *log.Logger {
+	return newStdLogger(unstructuredLogger, level, prefix, flags)
+}
+
+// RedirectStdLog sets the output of the standard logger of the log package, as used by log.Printf,
+// so that every message is logged as with NewStdLogger. The returned function restores the
+// previous output.
+func RedirectStdLog(unstructuredLogger UnstructuredLogger, level Level) func() {
+	return redirectStdLog(unstructuredLogger, level)
+}
+
+// NewLogrusHook returns a new logrus.Hook that logs every logrus.Entry as an UnstructuredEvent,
+// with the Data of the logrus.Entry as Fields. Trace entries are logged at the Debug Level.
+// As with any Logger, Fatal and Panic entries exit or panic once logged, before logrus
+// writes them itself, so the Out of the logrus.Logger is usually set to ioutil.Discard.
+func NewLogrusHook(unstructuredLogger UnstructuredLogger) logrus.Hook {
+	return newLogrusHook(unstructuredLogger)
+}
+
 // Logger is the main logging interface. A Logger logs Events with given Contexts as Entry objects.
 type Logger interface {
 	// WithContext returns a new Logger with the given Context attached. If the Context
 	// was not registered in the Specification on Logger creation, this method will panic.
 	WithContext(context Context) Logger
+	// Named returns a new Logger with the given name appended to the name of this Logger,
+	// separated by a period. The name selects the AtomicLevel in the LevelRegistry of the
+	// LoggerOptions, so that different subsystems can have separate levels.
+	Named(name string) Logger
 	// Unstructured returns the associated UnstructuredLogger. The methods on UnstructuredLogger
 	// are not directly included on Logger to discourage use of these methods.
 	Unstructured() UnstructuredLogger
@@ -213,11 +393,14 @@ type Logger interface {
 	Debug(event Event)
 	// Error prints an event at the Error Level.
 	Error(event Event)
-	// Fatal prints an event at the Fatal Level.
+	// Fatal prints an event at the Fatal Level, regardless of Filters, flushes the
+	// io.Writer if it has a Sync or Flush method, and then calls LoggerOptions.ExitFunc.
 	Fatal(event Event)
 	// Info prints an event at the Info Level.
 	Info(event Event)
-	// Panic prints an event at the Panic Level.
+	// Panic prints an event at the Panic Level, regardless of Filters, flushes the io.Writer
+	// if it has a Sync or Flush method, and then calls LoggerOptions.PanicFunc with the
+	// marshalled Entry.
 	Panic(event Event)
 	// Warn prints an event at the Warn Level.
 	Warn(event Event)
@@ -239,10 +422,18 @@ type Logger interface {
 	WarnWriter(event Event) io.Writer
 
 	// ErrorEvent is a convienence method that calls Error using an ErrorEvent
-	// if err is not nil.
+	// created with NewErrorEvent if err is not nil.
 	ErrorEvent(err error)
 }
 
+// NewErrorEvent returns a new ErrorEvent for the given error. The ErrorEvent records the message
+// and Go type of the error and of every error it wraps, following both Unwrap() error and
+// Unwrap() []error as used by errors.Join. Stack traces are recorded for errors that have a
+// StackTrace method, such as those created by github.com/pkg/errors.
+func NewErrorEvent(err error) *ErrorEvent {
+	return newErrorEvent(err)
+}
+
 // Entry is the type that is marshalled and unmarshalled into and from log messages.
 // Every log message is an Entry, including UnstructuredLogger messages.
 type Entry struct {
@@ -260,6 +451,26 @@ type Entry struct {
 	// WriterOutput is the associated writer output, if this Entry was used for a Writer function.
 	// If this Entry was created from a non-Writer function, this will be nil.
 	WriterOutput []byte
+	// Caller is the location that logged this Entry, if LoggerOptions.IncludeCaller was set.
+	Caller *Caller
+	// Stack is the stack trace of the goroutine that logged this Entry, if the Level of this
+	// Entry was at or above LoggerOptions.StackTraceLevel.
+	Stack string
+}
+
+// Caller is a location in source code that logged an Entry.
+type Caller struct {
+	// File is the full path of the source file.
+	File string `json:"file"`
+	// Line is the line number in File.
+	Line int `json:"line"`
+	// Function is the fully qualified name of the function.
+	Function string `json:"function"`
+}
+
+// String returns the Caller as file:line:function.
+func (c *Caller) String() string {
+	return fmt.Sprintf("%s:%d:%s", c.File, c.Line, c.Function)
 }
 
 // IDAllocator allocated unique IDs for Entry structs.
@@ -287,6 +498,232 @@ func NewRequireContextFilter(context Context) Filter {
 	)
 }
 
+// FilterFunc is an adapter to allow the use of ordinary functions as Filters.
+type FilterFunc func(entry *Entry) bool
+
+// Include returns f(entry).
+func (f FilterFunc) Include(entry *Entry) bool {
+	return f(entry)
+}
+
+// AndFilter returns a Filter that only selects Entry objects selected by all of the given Filters.
+func AndFilter(filters ...Filter) Filter {
+	return newAndFilter(
+		filters,
+	)
+}
+
+// OrFilter returns a Filter that only selects Entry objects selected by any of the given Filters.
+func OrFilter(filters ...Filter) Filter {
+	return newOrFilter(
+		filters,
+	)
+}
+
+// NotFilter returns a Filter that only selects Entry objects not selected by the given Filter.
+func NotFilter(filter Filter) Filter {
+	return newNotFilter(
+		filter,
+	)
+}
+
+// NewEventTypeFilter returns a Filter that only selects Entry objects with an Event of the
+// same type as the given Event. The type is specified using the zero value, as with Specification.
+func NewEventTypeFilter(event Event) Filter {
+	return newEventTypeFilter(
+		event,
+	)
+}
+
+// NewContextTypesFilter returns a Filter that only selects Entry objects with a Context of the
+// same type as any of the given Contexts. The types are specified using the zero value, as with Specification.
+func NewContextTypesFilter(contexts ...Context) Filter {
+	return newContextTypesFilter(
+		contexts,
+	)
+}
+
+// NewTimeRangeFilter returns a Filter that only selects Entry objects with a Time at or after start
+// and before end. A zero start or end leaves that side of the range unbounded.
+func NewTimeRangeFilter(start time.Time, end time.Time) Filter {
+	return newTimeRangeFilter(
+		start,
+		end,
+	)
+}
+
+// NewIDFilter returns a Filter that only selects the Entry with the given ID.
+func NewIDFilter(id string) Filter {
+	return newIDFilter(
+		id,
+	)
+}
+
+// NewWriterOutputFilter returns a Filter that only selects Entry objects with WriterOutput
+// matching the given regular expression.
+func NewWriterOutputFilter(regexp *regexp.Regexp) Filter {
+	return newWriterOutputFilter(
+		regexp,
+	)
+}
+
+// NewFieldFilter returns a Filter that only selects Entry objects with an UnstructuredEvent that has
+// a field of the given key equal to the given value, converted with NewFieldValue.
+func NewFieldFilter(key string, value interface{}) Filter {
+	return newFieldFilter(
+		key,
+		value,
+	)
+}
+
+// AtomicLevel is a Level that can be changed while Loggers are using it. An AtomicLevel
+// is a Filter that selects Entry objects at or above the Level, and an http.Handler that
+// returns the Level as JSON for GET requests, such as {"level":"info"}, and sets it from
+// the same JSON for PUT requests. AtomicLevels are safe for concurrent use.
+type AtomicLevel interface {
+	Filter
+	http.Handler
+	// Level returns the current Level.
+	Level() Level
+	// SetLevel sets the current Level.
+	SetLevel(level Level)
+}
+
+// NewAtomicLevel returns a new AtomicLevel set to the given Level.
+func NewAtomicLevel(level Level) AtomicLevel {
+	return newAtomicLevel(
+		level,
+	)
+}
+
+// LevelRegistry holds an AtomicLevel per Logger name, see LoggerOptions.LevelRegistry. A
+// LevelRegistry is an http.Handler that returns all Levels as JSON for GET requests, such as
+// {"levels":{"":"info","db":"debug"}}, and sets the Level for a name from JSON such as
+// {"name":"db","level":"debug"} for PUT requests. LevelRegistries are safe for concurrent use.
+type LevelRegistry interface {
+	http.Handler
+	// AtomicLevel returns the AtomicLevel for the given name, creating it with the
+	// default Level of the LevelRegistry if it does not exist yet.
+	AtomicLevel(name string) AtomicLevel
+	// Levels returns the current Level of every name.
+	Levels() map[string]Level
+}
+
+// NewLevelRegistry returns a new LevelRegistry that creates AtomicLevels with the given default Level.
+func NewLevelRegistry(defaultLevel Level) LevelRegistry {
+	return newLevelRegistry(
+		defaultLevel,
+	)
+}
+
+// SamplingFilter is a Filter that drops Entry objects to limit log volume, and counts the
+// Entry objects it dropped. SamplingFilters are safe for concurrent use. Entry objects with a
+// *SuppressedEvent are always included.
+type SamplingFilter interface {
+	Filter
+	// Suppressed returns the number of Entry objects not included per Level and Event type
+	// since the last call, and resets the counts.
+	Suppressed() []*SuppressedCount
+}
+
+// SamplingFilterOptions specifies the options to be used when creating a SamplingFilter.
+type SamplingFilterOptions struct {
+	// Timer specifies an alternate Timer to use.
+	// If not specified, a system Timer will be used.
+	Timer Timer
+}
+
+// NewEveryNFilter returns a SamplingFilter that includes the first of every n Entry objects per Event type.
+func NewEveryNFilter(n uint64) SamplingFilter {
+	return newEveryNFilter(
+		n,
+	)
+}
+
+// NewFirstNFilter returns a SamplingFilter that includes the first n Entry objects per Level and Event
+// type in every interval, and then one of every thereafter Entry objects. If thereafter is 0, no more
+// Entry objects are included until the next interval. If interval is 0, the counts are never reset,
+// and a negative interval is an error.
+func NewFirstNFilter(n uint64, thereafter uint64, interval time.Duration, options SamplingFilterOptions) (SamplingFilter, error) {
+	return newFirstNFilter(
+		n,
+		thereafter,
+		interval,
+		options,
+	)
+}
+
+// NewRateLimitFilter returns a SamplingFilter that limits Entry objects per Level, Event type and
+// Contexts with a token bucket that holds up to burst tokens and refills at rate tokens per second.
+func NewRateLimitFilter(rate float64, burst int, options SamplingFilterOptions) SamplingFilter {
+	return newRateLimitFilter(
+		rate,
+		burst,
+		options,
+	)
+}
+
+// SuppressedReporter periodically logs the counts of Entry objects suppressed by SamplingFilters.
+type SuppressedReporter interface {
+	// Stop stops reporting, after logging any remaining counts.
+	Stop()
+}
+
+// NewSuppressedReporter returns a new SuppressedReporter that logs a *SuppressedEvent at the Warn Level
+// to the given Logger every interval, with the counts of the given SamplingFilters merged. Nothing is
+// logged for an interval in which no Entry objects were suppressed. If interval is not positive,
+// DefaultSuppressedReportInterval is used.
+func NewSuppressedReporter(logger Logger, interval time.Duration, filters ...SamplingFilter) SuppressedReporter {
+	return newSuppressedReporter(
+		logger,
+		interval,
+		filters,
+	)
+}
+
+// ParseFilter parses a Filter from a text expression. Context and Event types are referred to by
+// their short type names, as registered in the Specification. For example:
+//
+//	level>=warn && event==FooEvent && ctx.RequestID=="abc" && time>"2026-01-01"
+//
+// Terms are joined with && and ||, negated with !, and grouped with parentheses. Each term
+// compares a field to a value with one of ==, =, !=, <, <=, >, >=, or the regular expression
+// operators =~ and !~. Values may be bare words or quoted strings. The fields are:
+//
+//	level             the Level, compared by severity, for example level>=warn
+//	event             the Event type name, for example event==FooEvent
+//	event.Field       a field of the Event, such as event.Code>=500
+//	ctx.Type          true if the Entry has a Context of the type, when used without a comparison
+//	ctx.Type          the value of a Context of the type, such as ctx.RequestID=="abc"
+//	ctx.Type.Field    a field of a Context of the type, such as ctx.User.Name=~"^a"
+//	field.key         true if the UnstructuredEvent has the field, when used without a comparison
+//	field.key         a field of an UnstructuredEvent, such as field.user=="alice" or field.latency>1.5s
+//	id                the Entry ID
+//	time              the Entry time, as RFC 3339 or 2006-01-02 15:04:05 or 2006-01-02, in UTC if no zone is given
+//	output            the WriterOutput
+//
+// Fields of structs, and pointers to structs, are accessed by name. Durations are compared with
+// values such as 1.5s and times with values as for time. Errors are returned as *FilterParseError.
+func ParseFilter(specification *Specification, expr string) (Filter, error) {
+	return parseFilter(
+		specification,
+		expr,
+	)
+}
+
+// FilterParseError is an error returned from ParseFilter.
+type FilterParseError struct {
+	// Position is the byte offset in the expression where the error was found.
+	Position int
+	// Message describes the error.
+	Message string
+}
+
+// Error returns the error message.
+func (f *FilterParseError) Error() string {
+	return fmt.Sprintf("ledge: filter parse error at position %d: %s", f.Position, f.Message)
+}
+
 // Marshaller marshals Entry objects into byte slices.
 type Marshaller interface {
 	// Marshal marshals Entry objects into byte slices.
@@ -303,6 +740,10 @@ type TextMarshallerOptions struct {
 	NoLevel bool
 	// NoContexts will suppress the printing of Entry Contexts.
 	NoContexts bool
+	// NoCaller will suppress the printing of Entry Callers.
+	NoCaller bool
+	// NoStack will suppress the printing of Entry stack traces.
+	NoStack bool
 }
 
 // NewLogrusTextMarshaller returns a Marshaller that uses Logrus' TextFormatter.
@@ -338,6 +779,180 @@ func NewTextMarshaller(options TextMarshallerOptions) Marshaller {
 	)
 }
 
+// JSONKeys specifies the keys of the values of an Entry in JSON. Keys that are not set
+// are the default keys id, time, level, event_type, writer_output, caller and stack.
+type JSONKeys struct {
+	ID           string
+	Time         string
+	Level        string
+	EventType    string
+	WriterOutput string
+	Caller       string
+	Stack        string
+}
+
+// JSONTimeFormat specifies how the time of an Entry is formatted in JSON.
+type JSONTimeFormat int
+
+const (
+	// JSONTimeFormatDefault formats times as strings with the layout
+	// 2006-01-02 15:04:05.999999999 -0700 MST.
+	JSONTimeFormatDefault JSONTimeFormat = iota
+	// JSONTimeFormatRFC3339Nano formats times as strings with time.RFC3339Nano.
+	JSONTimeFormatRFC3339Nano
+	// JSONTimeFormatUnixNano formats times as numbers of nanoseconds since the Unix epoch.
+	JSONTimeFormatUnixNano
+	// JSONTimeFormatLayout formats times as strings with JSONMarshallerOptions.TimeLayout.
+	JSONTimeFormatLayout
+	// JSONTimeFormatRFC3339 formats times as strings with time.RFC3339.
+	JSONTimeFormatRFC3339
+)
+
+// JSONMarshallerOptions specifies the options to be used when creating a JSON Marshaller.
+type JSONMarshallerOptions struct {
+	// Keys specifies alternate keys for the values of an Entry. The keys and ContextsKey
+	// must all be different.
+	Keys JSONKeys
+	// TimeFormat specifies how the time of an Entry is formatted.
+	TimeFormat JSONTimeFormat
+	// TimeLayout specifies the layout for JSONTimeFormatLayout, as with time.Format.
+	// It must be set if TimeFormat is JSONTimeFormatLayout.
+	TimeLayout string
+	// UpperCaseLevel specifies that Levels are marshalled in upper case, such as INFO.
+	// If not set, Levels are marshalled in lower case.
+	UpperCaseLevel bool
+	// ContextsKey specifies a key to nest Contexts under in a JSON object. If not set,
+	// Contexts are flattened into the top-level object, keyed by their type names.
+	ContextsKey string
+	// FullyQualifiedTypeNames specifies that Context and Event types are keyed by their
+	// fully qualified names, as with FullyQualifiedJSONMarshaller. If not set, short type
+	// names are used, as with JSONMarshaller.
+	FullyQualifiedTypeNames bool
+	// OmitEmptyWriterOutput specifies that the writer output is left out if empty.
+	OmitEmptyWriterOutput bool
+}
+
+// NewJSONMarshaller returns a new Marshaller that marshals Entries in JSON with the given options,
+// or an error if the options are invalid. Entry objects can be read with NewJSONUnmarshaller given
+// the same options.
+func NewJSONMarshaller(options JSONMarshallerOptions) (Marshaller, error) {
+	if err := validateJSONMarshallerOptions(options); err != nil {
+		return nil, err
+	}
+	return newJSONMarshaller(
+		options,
+	), nil
+}
+
+// GELFMarshallerOptions provides options for creating GELF Marshallers.
+type GELFMarshallerOptions struct {
+	// Host specifies the host of every message.
+	// If not specified, os.Hostname will be used.
+	Host string
+}
+
+// NewGELFMarshaller returns a Marshaller that marshals Entries in GELF 1.1 JSON, for Graylog.
+// The writer output, or the message of an UnstructuredEvent or ErrorEvent, is short_message,
+// or the Event type name if there is none, and stacks are full_message. The Level is a syslog
+// severity. Contexts, Events and the Fields of an UnstructuredEvent are flattened into additional
+// fields prefixed with _, with nested keys joined by _, along with _entry_id, _event_type and the
+// Caller as _file, _line and _function. Values that are not strings or numbers are marshalled as strings.
+// Messages are not chunked or compressed as needed for GELF over UDP.
+func NewGELFMarshaller(options GELFMarshallerOptions) Marshaller {
+	return newGELFMarshaller(
+		options,
+	)
+}
+
+// SyslogFacility is a syslog facility as defined in RFC 5424.
+type SyslogFacility int
+
+// SyslogFacility values.
+const (
+	SyslogFacilityKern SyslogFacility = iota
+	SyslogFacilityUser
+	SyslogFacilityMail
+	SyslogFacilityDaemon
+	SyslogFacilityAuth
+	SyslogFacilitySyslog
+	SyslogFacilityLPR
+	SyslogFacilityNews
+	SyslogFacilityUUCP
+	SyslogFacilityCron
+	SyslogFacilityAuthPriv
+	SyslogFacilityFTP
+	SyslogFacilityLocal0 SyslogFacility = iota + 4
+	SyslogFacilityLocal1
+	SyslogFacilityLocal2
+	SyslogFacilityLocal3
+	SyslogFacilityLocal4
+	SyslogFacilityLocal5
+	SyslogFacilityLocal6
+	SyslogFacilityLocal7
+)
+
+// SyslogMarshallerOptions provides options for creating syslog Marshallers.
+type SyslogMarshallerOptions struct {
+	// Facility specifies the facility of every message.
+	// If not specified, SyslogFacilityUser will be used, as SyslogFacilityKern is reserved for the kernel.
+	Facility SyslogFacility
+	// Hostname specifies the HOSTNAME of every message.
+	// If not specified, os.Hostname will be used.
+	Hostname string
+	// AppName specifies the APP-NAME of every message.
+	// If not specified, the base name of os.Args[0] will be used.
+	AppName string
+	// ProcID specifies the PROCID of every message.
+	// If not specified, os.Getpid will be used.
+	ProcID string
+	// SDID specifies the SD-ID of the STRUCTURED-DATA element Contexts are written to.
+	// If not specified, ledge@32473 will be used.
+	SDID string
+}
+
+// NewSyslogMarshaller returns a Marshaller that marshals Entries as RFC 5424 syslog messages.
+// The PRI is computed from the facility and the Level as a syslog severity, the MSGID is the
+// Event type name, and Contexts are flattened with nested keys joined by . into the params of a
+// single STRUCTURED-DATA element. The MSG is the writer output, the message and Fields of an
+// UnstructuredEvent, or the Event as marshalled by the text Marshallers, followed by any stacks.
+//
+// Use with NewSyslogWriter to send Entry objects to a syslog daemon.
+func NewSyslogMarshaller(options SyslogMarshallerOptions) Marshaller {
+	return newSyslogMarshaller(
+		options,
+	)
+}
+
+// NewSyslogWriter returns a new io.WriteCloser that sends every call to Write as one syslog
+// message to the address on the network, with a trailing newline removed. The network must be
+// one of unixgram, unix, udp or tcp. Messages on stream networks are framed with octet counting
+// as in RFC 6587. If a write fails, the writer reconnects and retries once.
+//
+// A Logger writes each encoded Entry with a single call to Write, so this can be used as the
+// io.Writer of a Logger with a Marshaller from NewSyslogMarshaller and no Encoder.
+func NewSyslogWriter(network string, address string) (io.WriteCloser, error) {
+	return newSyslogWriter(
+		network,
+		address,
+	)
+}
+
+// NewLogfmtMarshaller returns a Marshaller that marshals Entries in logfmt, as key=value pairs
+// on a single line. Contexts and Events are marshalled with encoding/json and flattened, so that
+// a FooEvent{Bar: Baz{One: "one"}} is marshalled as FooEvent.Bar.One=one, and a RequestID as
+// RequestID=value. Arrays are marshalled as JSON. The Fields of an UnstructuredEvent are marshalled
+// as their own keys after msg, prefixed with fields., so that they can not clash with the keys of
+// the Entry or its Contexts.
+// Values are quoted with strconv.Quote when needed, and strings that would otherwise read as
+// numbers, bools or JSON are quoted as well.
+//
+// Entry objects can be read with NewLogfmtUnmarshaller, with suppressed values left as zero values.
+func NewLogfmtMarshaller(options TextMarshallerOptions) Marshaller {
+	return newLogfmtMarshaller(
+		options,
+	)
+}
+
 // Encoder encodes marshalled byte slices to a writer, optionally adding output.
 type Encoder interface {
 	// Encode encodes marshalled byte slices to a writer, optionally adding output.
@@ -392,6 +1007,23 @@ type LoggerOptions struct {
 	// are errors writing to the main io.Writer specified on Logger creation.
 	// Otherwise, we have a recursive problem - how do you log an error for a log error?
 	BackupWriter io.Writer
+	// ExitFunc specifies the function called with exit code 1 after a Fatal Entry is written.
+	// If not specified, os.Exit will be used.
+	ExitFunc func(code int)
+	// PanicFunc specifies the function called with the marshalled Entry as a string after a
+	// Panic Entry is written. If not specified, the builtin panic will be used.
+	PanicFunc func(value interface{})
+	// IncludeCaller specifies that the file, line and function that called the Logger,
+	// or wrote to a Writer function, are recorded in Entry.Caller.
+	IncludeCaller bool
+	// StackTraceLevel specifies that a stack trace of the logging goroutine is recorded in
+	// Entry.Stack for Entry objects at or above this Level. If not specified, which is
+	// Level_NONE, no stack traces are recorded.
+	StackTraceLevel Level
+	// LevelRegistry specifies a LevelRegistry to get the Level of the Logger from, by the
+	// name given with Logger.Named. The Logger returned by NewLogger has the empty name.
+	// If not specified, only Filters are used.
+	LevelRegistry LevelRegistry
 }
 
 // NewLogger creates a new Logger.
@@ -405,7 +1037,139 @@ func NewLogger(writer io.Writer, marshaller Marshaller, specification *Specifica
 		marshaller,
 		reflectTypeProvider,
 		options,
+		"",
 		make([]Context, 0),
+		nil,
+	), nil
+}
+
+// OverflowPolicy specifies what an AsyncLogger does when its queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowPolicyBlock blocks the logging call until there is room in the queue.
+	OverflowPolicyBlock OverflowPolicy = iota
+	// OverflowPolicyDropNewest drops the Entry being logged.
+	OverflowPolicyDropNewest
+	// OverflowPolicyDropOldest drops the oldest queued Entry to make room for the Entry being logged.
+	OverflowPolicyDropOldest
+)
+
+// AsyncLoggerOptions specifies the options to be used when creating an AsyncLogger.
+type AsyncLoggerOptions struct {
+	// QueueSize specifies the maximum number of Entry objects to buffer.
+	// If not specified, DefaultAsyncQueueSize will be used.
+	QueueSize int
+	// OverflowPolicy specifies what to do when the queue is full.
+	// If not specified, OverflowPolicyBlock will be used.
+	OverflowPolicy OverflowPolicy
+}
+
+// AsyncLogger is a Logger that marshals and writes Entry objects on a background goroutine,
+// so that a slow io.Writer does not block the caller. Fatal and Panic Entry objects are written
+// synchronously after everything queued before them. Errors from the background goroutine are
+// written to the BackupWriter, or os.Stderr if no BackupWriter is specified.
+type AsyncLogger interface {
+	Logger
+
+	// Flush blocks until every Entry logged before the call is written, or until the timeout
+	// elapses, in which case an error is returned. A timeout of 0 means no timeout.
+	Flush(timeout time.Duration) error
+	// Close writes every queued Entry and stops the background goroutine.
+	// Entry objects logged after Close are dropped.
+	Close() error
+	// Dropped returns the number of Entry objects dropped because the queue was full or closed.
+	Dropped() uint64
+}
+
+// NewAsyncLogger creates a new AsyncLogger.
+func NewAsyncLogger(
+	writer io.Writer,
+	marshaller Marshaller,
+	specification *Specification,
+	options LoggerOptions,
+	asyncOptions AsyncLoggerOptions,
+) (AsyncLogger, error) {
+	reflectTypeProvider, err := newReflectTypeProvider(specification)
+	if err != nil {
+		return nil, err
+	}
+	return newAsyncLogger(
+		writer,
+		marshaller,
+		reflectTypeProvider,
+		options,
+		asyncOptions,
+	), nil
+}
+
+// RotatingFileWriterOptions specifies the options to be used when creating a RotatingFileWriter.
+type RotatingFileWriterOptions struct {
+	// MaxSize specifies the size in bytes a file may grow to before it is rotated.
+	// If not specified, files will not be rotated by size.
+	MaxSize int64
+	// Interval specifies how long a file is written to before it is rotated.
+	// If not specified, files will not be rotated by time.
+	Interval time.Duration
+	// MaxFiles specifies the maximum number of rotated files to keep.
+	// If not specified, rotated files will not be removed by count.
+	MaxFiles int
+	// MaxAge specifies the maximum age of rotated files to keep.
+	// If not specified, rotated files will not be removed by age.
+	MaxAge time.Duration
+	// Compress specifies that rotated files should be gzipped.
+	Compress bool
+	// Timer specifies an alternate Timer to use.
+	// If not specified, a system Timer will be used.
+	Timer Timer
+	// BackupWriter specifies a location to write errors to if rotating fails during a
+	// Write, which still writes to the file. If not specified, os.Stderr will be used.
+	BackupWriter io.Writer
+}
+
+// RotatingFileWriter is an io.WriteCloser that writes to a file, rotating it by size or time.
+// Rotated files are named after the file with the UTC rotation time appended, and a .gz
+// suffix if compressed. A file is only ever rotated between calls to Write, and a Logger
+// writes each encoded Entry with a single call to Write, so Entry objects are never split
+// across files.
+type RotatingFileWriter interface {
+	io.WriteCloser
+	// Rotate rotates the file immediately, if it is not empty.
+	Rotate() error
+}
+
+// NewRotatingFileWriter returns a new RotatingFileWriter that writes to the file at path.
+func NewRotatingFileWriter(path string, options RotatingFileWriterOptions) (RotatingFileWriter, error) {
+	return newRotatingFileWriter(
+		path,
+		options,
+	)
+}
+
+// RotatedFilePaths returns the paths of the files rotated by a RotatingFileWriter for
+// the file at path, oldest first, followed by path itself if it exists.
+func RotatedFilePaths(path string) ([]string, error) {
+	return getRotatedFilePaths(path)
+}
+
+// NewMultiFileReader returns a new io.ReadCloser that reads the files at the given paths
+// in order, as if they were one file. Files ending in .gz are decompressed.
+func NewMultiFileReader(paths ...string) io.ReadCloser {
+	return newMultiFileReader(
+		paths,
+	)
+}
+
+// NewRotatedFileReader returns a new io.ReadCloser that reads the files returned from
+// RotatedFilePaths in order. This can be used to read all Entry objects written with
+// a RotatingFileWriter with an EntryReader.
+func NewRotatedFileReader(path string) (io.ReadCloser, error) {
+	paths, err := getRotatedFilePaths(path)
+	if err != nil {
+		return nil, err
+	}
+	return newMultiFileReader(
+		paths,
 	), nil
 }
 
@@ -415,11 +1179,60 @@ type Unmarshaller interface {
 	Unmarshal(p []byte) (*Entry, error)
 }
 
+// UnmarshallerOptions specifies the options to be used when creating an Unmarshaller.
+type UnmarshallerOptions struct {
+	// Lenient specifies that Context and Event types that are not part of the Specification
+	// are unmarshalled as *RawContext and *RawEvent values. If not set, unmarshalling an Entry
+	// with an unknown type returns an error.
+	Lenient bool
+}
+
 // NewProtoUnmarshaller returns a new Unmarshaller that unmarshals Entry Objects
-// marshalled with ProtoMarshaller.
+// marshalled with ProtoMarshaller or BinaryProtoMarshaller.
 func NewProtoUnmarshaller(specification *Specification) (Unmarshaller, error) {
+	return NewProtoUnmarshallerWithOptions(specification, UnmarshallerOptions{})
+}
+
+// NewProtoUnmarshallerWithOptions returns a new Unmarshaller that unmarshals Entry Objects
+// marshalled with ProtoMarshaller or BinaryProtoMarshaller, using the given options.
+func NewProtoUnmarshallerWithOptions(specification *Specification, options UnmarshallerOptions) (Unmarshaller, error) {
 	return newProtoUnmarshaller(
 		specification,
+		options,
+	)
+}
+
+// JSONUnmarshallerOptions specifies the options to be used when creating a JSON Unmarshaller.
+type JSONUnmarshallerOptions struct {
+	UnmarshallerOptions
+	// MarshallerOptions specifies the options of the Marshaller the Entry objects were
+	// marshalled with, as passed to NewJSONMarshaller. Entry objects marshalled with
+	// FullyQualifiedJSONMarshaller are read with MarshallerOptions.FullyQualifiedTypeNames set.
+	MarshallerOptions JSONMarshallerOptions
+}
+
+// NewJSONUnmarshaller returns a new Unmarshaller that unmarshals Entry objects
+// marshalled with JSONMarshaller, FullyQualifiedJSONMarshaller, or a Marshaller
+// returned by NewJSONMarshaller.
+func NewJSONUnmarshaller(specification *Specification, options JSONUnmarshallerOptions) (Unmarshaller, error) {
+	return newJSONUnmarshaller(
+		specification,
+		options,
+	)
+}
+
+// NewLogfmtUnmarshaller returns a new Unmarshaller that unmarshals Entry objects marshalled
+// with LogfmtMarshaller, one per line, which can be read with RPCDecoder. Contexts and Events
+// are unmarshalled by their short type names with encoding/json, using the types in the Specification
+// to tell strings from other values. With UnmarshallerOptions.Lenient set, unknown types are returned as
+// *RawContext and *RawEvent values with RawFormatJSON.
+//
+// Keys of an UnstructuredEvent that are not Context types are unmarshalled as its Fields. Quoted
+// values are read as strings, and times, durations and bytes are read back as strings.
+func NewLogfmtUnmarshaller(specification *Specification, options UnmarshallerOptions) (Unmarshaller, error) {
+	return newLogfmtUnmarshaller(
+		specification,
+		options,
 	)
 }
 
@@ -441,7 +1254,7 @@ type EntryResponse struct {
 type EntryReader interface {
 	// Channel returns a read channel of EntryResponse objects.
 	Channel() <-chan *EntryResponse
-	// Cancel cancels reading and will close the channel.
+	// Cancel cancels reading and will close the channel. Cancel may be called more than once.
 	Cancel() error
 }
 
@@ -449,6 +1262,14 @@ type EntryReader interface {
 type EntryReaderOptions struct {
 	// Filters specifies the Filters to use.
 	Filters []Filter
+	// Follow specifies that reading should not stop at the end of the input stream, but
+	// wait for more data to be appended, like tail -f. If the io.Reader is an *os.File,
+	// truncation of the file, and replacement of the file at its path as with log rotation,
+	// are detected and the file is reopened. Reading only stops on Cancel.
+	Follow bool
+	// FollowInterval specifies how often to check for more data in Follow mode.
+	// If not specified, DefaultFollowInterval will be used.
+	FollowInterval time.Duration
 }
 
 // NewEntryReader returns a new EntryReader.
@@ -487,6 +1308,10 @@ type FakeLogger interface {
 
 	// CheckEntriesEqual returns error if the expected Entry objects do not match the logged Entry objects.
 	CheckEntriesEqual(expected []*Entry, checkID bool, checkTime bool) error
+
+	// ExitCode returns the exit code of the last Fatal call and true, or false if Fatal was not
+	// called. A FakeLogger does not exit on Fatal, so that tests can check fatal paths.
+	ExitCode() (int, bool)
 }
 
 // NewFakeLogger returns a new FakeLogger.
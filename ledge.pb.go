@@ -10,7 +10,13 @@ It is generated from these files:
 It has these top-level messages:
 	UnstructuredEvent
 	ErrorEvent
+	ErrorCause
+	SuppressedCount
+	SuppressedEvent
+	ProtoContext
+	ProtoCaller
 	ProtoEntry
+	FieldValue
 */
 package ledge
 
@@ -65,8 +71,49 @@ func (x Level) String() string {
 }
 func (Level) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
 
+// FieldType is the type of a FieldValue.
+type FieldType int32
+
+const (
+	FieldType_STRING FieldType = 0
+	FieldType_INT    FieldType = 1
+	FieldType_FLOAT  FieldType = 2
+	FieldType_BOOL   FieldType = 3
+	FieldType_BYTES  FieldType = 4
+	// TIME is stored in int_value as nanoseconds since the unix epoch.
+	FieldType_TIME FieldType = 5
+	// DURATION is stored in int_value as nanoseconds.
+	FieldType_DURATION FieldType = 6
+)
+
+var FieldType_name = map[int32]string{
+	0: "STRING",
+	1: "INT",
+	2: "FLOAT",
+	3: "BOOL",
+	4: "BYTES",
+	5: "TIME",
+	6: "DURATION",
+}
+var FieldType_value = map[string]int32{
+	"STRING":   0,
+	"INT":      1,
+	"FLOAT":    2,
+	"BOOL":     3,
+	"BYTES":    4,
+	"TIME":     5,
+	"DURATION": 6,
+}
+
+func (x FieldType) String() string {
+	return proto.EnumName(FieldType_name, int32(x))
+}
+func (FieldType) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
 type UnstructuredEvent struct {
 	Msg string `protobuf:"bytes,1,opt,name=msg" json:"msg,omitempty"`
+	// fields are the Fields of the UnstructuredLogger that logged this event.
+	Fields map[string]*FieldValue `protobuf:"bytes,2,rep,name=fields" json:"fields,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
 
 func (m *UnstructuredEvent) Reset()                    { *m = UnstructuredEvent{} }
@@ -81,8 +128,19 @@ func (m *UnstructuredEvent) GetMsg() string {
 	return ""
 }
 
+func (m *UnstructuredEvent) GetFields() map[string]*FieldValue {
+	if m != nil {
+		return m.Fields
+	}
+	return nil
+}
+
 type ErrorEvent struct {
-	Msg string `protobuf:"bytes,1,opt,name=msg" json:"msg,omitempty"`
+	Msg      string `protobuf:"bytes,1,opt,name=msg" json:"msg,omitempty"`
+	TypeName string `protobuf:"bytes,2,opt,name=type_name,json=typeName" json:"type_name,omitempty"`
+	Stack    string `protobuf:"bytes,3,opt,name=stack" json:"stack,omitempty"`
+	// causes are the wrapped errors in depth-first order.
+	Causes []*ErrorCause `protobuf:"bytes,4,rep,name=causes" json:"causes,omitempty"`
 }
 
 func (m *ErrorEvent) Reset()                    { *m = ErrorEvent{} }
@@ -97,6 +155,172 @@ func (m *ErrorEvent) GetMsg() string {
 	return ""
 }
 
+func (m *ErrorEvent) GetTypeName() string {
+	if m != nil {
+		return m.TypeName
+	}
+	return ""
+}
+
+func (m *ErrorEvent) GetStack() string {
+	if m != nil {
+		return m.Stack
+	}
+	return ""
+}
+
+func (m *ErrorEvent) GetCauses() []*ErrorCause {
+	if m != nil {
+		return m.Causes
+	}
+	return nil
+}
+
+type ErrorCause struct {
+	// depth is 1 for an error wrapped by the ErrorEvent error, 2 for an error wrapped by that error, and so on.
+	Depth    int32  `protobuf:"varint,1,opt,name=depth" json:"depth,omitempty"`
+	Msg      string `protobuf:"bytes,2,opt,name=msg" json:"msg,omitempty"`
+	TypeName string `protobuf:"bytes,3,opt,name=type_name,json=typeName" json:"type_name,omitempty"`
+	Stack    string `protobuf:"bytes,4,opt,name=stack" json:"stack,omitempty"`
+}
+
+func (m *ErrorCause) Reset()                    { *m = ErrorCause{} }
+func (m *ErrorCause) String() string            { return proto.CompactTextString(m) }
+func (*ErrorCause) ProtoMessage()               {}
+func (*ErrorCause) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *ErrorCause) GetDepth() int32 {
+	if m != nil {
+		return m.Depth
+	}
+	return 0
+}
+
+func (m *ErrorCause) GetMsg() string {
+	if m != nil {
+		return m.Msg
+	}
+	return ""
+}
+
+func (m *ErrorCause) GetTypeName() string {
+	if m != nil {
+		return m.TypeName
+	}
+	return ""
+}
+
+func (m *ErrorCause) GetStack() string {
+	if m != nil {
+		return m.Stack
+	}
+	return ""
+}
+
+type SuppressedCount struct {
+	Level         Level  `protobuf:"varint,1,opt,name=level,enum=ledge.Level" json:"level,omitempty"`
+	EventTypeName string `protobuf:"bytes,2,opt,name=event_type_name,json=eventTypeName" json:"event_type_name,omitempty"`
+	Count         uint64 `protobuf:"varint,3,opt,name=count" json:"count,omitempty"`
+}
+
+func (m *SuppressedCount) Reset()                    { *m = SuppressedCount{} }
+func (m *SuppressedCount) String() string            { return proto.CompactTextString(m) }
+func (*SuppressedCount) ProtoMessage()               {}
+func (*SuppressedCount) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *SuppressedCount) GetLevel() Level {
+	if m != nil {
+		return m.Level
+	}
+	return Level_NONE
+}
+
+func (m *SuppressedCount) GetEventTypeName() string {
+	if m != nil {
+		return m.EventTypeName
+	}
+	return ""
+}
+
+func (m *SuppressedCount) GetCount() uint64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type SuppressedEvent struct {
+	Counts []*SuppressedCount `protobuf:"bytes,1,rep,name=counts" json:"counts,omitempty"`
+}
+
+func (m *SuppressedEvent) Reset()                    { *m = SuppressedEvent{} }
+func (m *SuppressedEvent) String() string            { return proto.CompactTextString(m) }
+func (*SuppressedEvent) ProtoMessage()               {}
+func (*SuppressedEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *SuppressedEvent) GetCounts() []*SuppressedCount {
+	if m != nil {
+		return m.Counts
+	}
+	return nil
+}
+
+type ProtoContext struct {
+	TypeName string `protobuf:"bytes,1,opt,name=type_name,json=typeName" json:"type_name,omitempty"`
+	Context  []byte `protobuf:"bytes,2,opt,name=context,proto3" json:"context,omitempty"`
+}
+
+func (m *ProtoContext) Reset()                    { *m = ProtoContext{} }
+func (m *ProtoContext) String() string            { return proto.CompactTextString(m) }
+func (*ProtoContext) ProtoMessage()               {}
+func (*ProtoContext) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *ProtoContext) GetTypeName() string {
+	if m != nil {
+		return m.TypeName
+	}
+	return ""
+}
+
+func (m *ProtoContext) GetContext() []byte {
+	if m != nil {
+		return m.Context
+	}
+	return nil
+}
+
+type ProtoCaller struct {
+	File     string `protobuf:"bytes,1,opt,name=file" json:"file,omitempty"`
+	Line     int64  `protobuf:"varint,2,opt,name=line" json:"line,omitempty"`
+	Function string `protobuf:"bytes,3,opt,name=function" json:"function,omitempty"`
+}
+
+func (m *ProtoCaller) Reset()                    { *m = ProtoCaller{} }
+func (m *ProtoCaller) String() string            { return proto.CompactTextString(m) }
+func (*ProtoCaller) ProtoMessage()               {}
+func (*ProtoCaller) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *ProtoCaller) GetFile() string {
+	if m != nil {
+		return m.File
+	}
+	return ""
+}
+
+func (m *ProtoCaller) GetLine() int64 {
+	if m != nil {
+		return m.Line
+	}
+	return 0
+}
+
+func (m *ProtoCaller) GetFunction() string {
+	if m != nil {
+		return m.Function
+	}
+	return ""
+}
+
 type ProtoEntry struct {
 	Id                       string            `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
 	TimeUnixNsec             int64             `protobuf:"varint,2,opt,name=time_unix_nsec,json=timeUnixNsec" json:"time_unix_nsec,omitempty"`
@@ -105,12 +329,17 @@ type ProtoEntry struct {
 	EventTypeName            string            `protobuf:"bytes,5,opt,name=event_type_name,json=eventTypeName" json:"event_type_name,omitempty"`
 	Event                    []byte            `protobuf:"bytes,6,opt,name=event,proto3" json:"event,omitempty"`
 	WriterOutput             []byte            `protobuf:"bytes,7,opt,name=writer_output,json=writerOutput,proto3" json:"writer_output,omitempty"`
+	// contexts are in the order they were attached, including repeated types.
+	// context_type_name_to_context is still written for older readers.
+	Contexts []*ProtoContext `protobuf:"bytes,8,rep,name=contexts" json:"contexts,omitempty"`
+	Caller   *ProtoCaller    `protobuf:"bytes,9,opt,name=caller" json:"caller,omitempty"`
+	Stack    string          `protobuf:"bytes,10,opt,name=stack" json:"stack,omitempty"`
 }
 
 func (m *ProtoEntry) Reset()                    { *m = ProtoEntry{} }
 func (m *ProtoEntry) String() string            { return proto.CompactTextString(m) }
 func (*ProtoEntry) ProtoMessage()               {}
-func (*ProtoEntry) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+func (*ProtoEntry) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
 
 func (m *ProtoEntry) GetId() string {
 	if m != nil {
@@ -161,39 +390,149 @@ func (m *ProtoEntry) GetWriterOutput() []byte {
 	return nil
 }
 
+func (m *ProtoEntry) GetContexts() []*ProtoContext {
+	if m != nil {
+		return m.Contexts
+	}
+	return nil
+}
+
+func (m *ProtoEntry) GetCaller() *ProtoCaller {
+	if m != nil {
+		return m.Caller
+	}
+	return nil
+}
+
+func (m *ProtoEntry) GetStack() string {
+	if m != nil {
+		return m.Stack
+	}
+	return ""
+}
+
+type FieldValue struct {
+	Type        FieldType `protobuf:"varint,1,opt,name=type,enum=ledge.FieldType" json:"type,omitempty"`
+	StringValue string    `protobuf:"bytes,2,opt,name=string_value,json=stringValue" json:"string_value,omitempty"`
+	IntValue    int64     `protobuf:"varint,3,opt,name=int_value,json=intValue" json:"int_value,omitempty"`
+	FloatValue  float64   `protobuf:"fixed64,4,opt,name=float_value,json=floatValue" json:"float_value,omitempty"`
+	BoolValue   bool      `protobuf:"varint,5,opt,name=bool_value,json=boolValue" json:"bool_value,omitempty"`
+	BytesValue  []byte    `protobuf:"bytes,6,opt,name=bytes_value,json=bytesValue,proto3" json:"bytes_value,omitempty"`
+}
+
+func (m *FieldValue) Reset()                    { *m = FieldValue{} }
+func (m *FieldValue) String() string            { return proto.CompactTextString(m) }
+func (*FieldValue) ProtoMessage()               {}
+func (*FieldValue) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *FieldValue) GetType() FieldType {
+	if m != nil {
+		return m.Type
+	}
+	return FieldType_STRING
+}
+
+func (m *FieldValue) GetStringValue() string {
+	if m != nil {
+		return m.StringValue
+	}
+	return ""
+}
+
+func (m *FieldValue) GetIntValue() int64 {
+	if m != nil {
+		return m.IntValue
+	}
+	return 0
+}
+
+func (m *FieldValue) GetFloatValue() float64 {
+	if m != nil {
+		return m.FloatValue
+	}
+	return 0
+}
+
+func (m *FieldValue) GetBoolValue() bool {
+	if m != nil {
+		return m.BoolValue
+	}
+	return false
+}
+
+func (m *FieldValue) GetBytesValue() []byte {
+	if m != nil {
+		return m.BytesValue
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*UnstructuredEvent)(nil), "ledge.UnstructuredEvent")
 	proto.RegisterType((*ErrorEvent)(nil), "ledge.ErrorEvent")
+	proto.RegisterType((*ErrorCause)(nil), "ledge.ErrorCause")
+	proto.RegisterType((*SuppressedCount)(nil), "ledge.SuppressedCount")
+	proto.RegisterType((*SuppressedEvent)(nil), "ledge.SuppressedEvent")
+	proto.RegisterType((*ProtoContext)(nil), "ledge.ProtoContext")
+	proto.RegisterType((*ProtoCaller)(nil), "ledge.ProtoCaller")
 	proto.RegisterType((*ProtoEntry)(nil), "ledge.ProtoEntry")
+	proto.RegisterType((*FieldValue)(nil), "ledge.FieldValue")
 	proto.RegisterEnum("ledge.Level", Level_name, Level_value)
+	proto.RegisterEnum("ledge.FieldType", FieldType_name, FieldType_value)
 }
 
 func init() { proto.RegisterFile("ledge.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 379 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x92, 0x4f, 0x8f, 0x94, 0x40,
-	0x10, 0xc5, 0x05, 0x86, 0xd1, 0xad, 0x65, 0xc7, 0xb6, 0xe3, 0xa1, 0x63, 0xd4, 0x90, 0xf1, 0x4f,
-	0x88, 0x87, 0x31, 0x59, 0x2f, 0xc6, 0x1b, 0xae, 0xac, 0xd9, 0xb8, 0x81, 0xb5, 0x33, 0x13, 0x8f,
-	0x64, 0x84, 0xca, 0x86, 0x2c, 0x74, 0x93, 0xa6, 0x19, 0x87, 0x4f, 0xe0, 0xd7, 0x36, 0xdd, 0x30,
-	0x99, 0xd3, 0x78, 0x7b, 0xf5, 0x7b, 0x14, 0x55, 0xf5, 0xd2, 0x70, 0x5e, 0x63, 0x79, 0x8f, 0xab,
-	0x56, 0x49, 0x2d, 0xa9, 0x6f, 0x8b, 0xe5, 0x3b, 0x78, 0xb6, 0x11, 0x9d, 0x56, 0x7d, 0xa1, 0x7b,
-	0x85, 0x65, 0xb2, 0x43, 0xa1, 0x29, 0x01, 0xaf, 0xe9, 0xee, 0x99, 0x13, 0x3a, 0xd1, 0x19, 0x37,
-	0x72, 0xf9, 0x1a, 0x20, 0x51, 0x4a, 0xaa, 0x53, 0xfe, 0x5f, 0x0f, 0xe0, 0xce, 0xfc, 0x37, 0x11,
-	0x5a, 0x0d, 0x74, 0x01, 0x6e, 0x55, 0x4e, 0xbe, 0x5b, 0x95, 0xf4, 0x2d, 0x2c, 0x74, 0xd5, 0x60,
-	0xde, 0x8b, 0x6a, 0x9f, 0x8b, 0x0e, 0x0b, 0xe6, 0x86, 0x4e, 0xe4, 0xf1, 0xc0, 0xd0, 0x8d, 0xa8,
-	0xf6, 0x69, 0x87, 0x05, 0x5d, 0x82, 0x5f, 0xe3, 0x0e, 0x6b, 0xe6, 0x85, 0x4e, 0xb4, 0xb8, 0x0c,
-	0x56, 0xe3, 0xbe, 0xb7, 0x86, 0xf1, 0xd1, 0xa2, 0x12, 0x5e, 0x16, 0x52, 0x68, 0xdc, 0xeb, 0x5c,
-	0x0f, 0x2d, 0xe6, 0x62, 0xdb, 0x60, 0xae, 0x65, 0x3e, 0x41, 0x36, 0x0b, 0xbd, 0xe8, 0xfc, 0xf2,
-	0xe3, 0xd4, 0x7a, 0x5c, 0x69, 0x75, 0x35, 0x7e, 0xb0, 0x1e, 0x5a, 0x4c, 0xb7, 0x0d, 0xae, 0xe5,
-	0x04, 0xac, 0xcb, 0x59, 0x71, 0xc2, 0xa6, 0xef, 0xe1, 0x29, 0x9a, 0xa3, 0x8f, 0xe3, 0x98, 0x6f,
-	0xef, 0xba, 0xb0, 0xf8, 0xd0, 0x40, 0x9f, 0x83, 0x6f, 0x01, 0x9b, 0x87, 0x4e, 0x14, 0xf0, 0xb1,
-	0xa0, 0x6f, 0xe0, 0xe2, 0x8f, 0xaa, 0x34, 0xaa, 0x5c, 0xf6, 0xba, 0xed, 0x35, 0x7b, 0x6c, 0xdd,
-	0x60, 0x84, 0x99, 0x65, 0x2f, 0x7e, 0xc0, 0xab, 0xff, 0x6e, 0x67, 0xf2, 0x7e, 0xc0, 0xe1, 0x90,
-	0xf7, 0x03, 0x0e, 0x66, 0xda, 0x6e, 0x5b, 0xf7, 0x68, 0x73, 0x0c, 0xf8, 0x58, 0x7c, 0x71, 0x3f,
-	0x3b, 0x1f, 0x7e, 0x82, 0x6f, 0x03, 0xa3, 0x4f, 0x60, 0x96, 0x66, 0x69, 0x42, 0x1e, 0xd1, 0x33,
-	0xf0, 0xbf, 0x25, 0x5f, 0x37, 0xdf, 0x89, 0x63, 0xe0, 0x4d, 0x7a, 0x9d, 0x11, 0xd7, 0xa8, 0x5f,
-	0x31, 0x4f, 0x89, 0x67, 0xec, 0x84, 0xf3, 0x8c, 0x93, 0x99, 0x91, 0xd7, 0xf1, 0x3a, 0xbe, 0x25,
-	0xbe, 0x91, 0x77, 0x71, 0x7a, 0x73, 0x45, 0xe6, 0xbf, 0xe7, 0xf6, 0xc5, 0x7c, 0xfa, 0x17, 0x00,
-	0x00, 0xff, 0xff, 0xfb, 0xb6, 0xad, 0x6c, 0x40, 0x02, 0x00, 0x00,
+	// 793 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x55, 0xcd, 0x4e, 0xf3, 0x46,
+	0x14, 0xfd, 0x1c, 0xff, 0x60, 0xdf, 0xf8, 0x03, 0x33, 0x45, 0x95, 0x05, 0x45, 0x4d, 0x5d, 0xd4,
+	0x52, 0x16, 0x41, 0xa2, 0x9b, 0xaa, 0xea, 0x26, 0x04, 0x83, 0xa2, 0xa6, 0x36, 0x0c, 0x4e, 0x7f,
+	0x56, 0x56, 0x70, 0x26, 0xa9, 0x85, 0x63, 0x47, 0xf6, 0x98, 0x12, 0xa9, 0x2f, 0xd5, 0x67, 0xe9,
+	0xdb, 0x74, 0x55, 0xcd, 0x8f, 0x89, 0xa1, 0xa4, 0xbb, 0xb9, 0xe7, 0xdc, 0x39, 0xf7, 0xf8, 0xde,
+	0x99, 0x31, 0x74, 0x33, 0x32, 0x5b, 0x90, 0xfe, 0xaa, 0x2c, 0x68, 0x81, 0x74, 0x1e, 0x78, 0x7f,
+	0x29, 0xb0, 0x3f, 0xc9, 0x2b, 0x5a, 0xd6, 0x09, 0xad, 0x4b, 0x32, 0xf3, 0x9f, 0x48, 0x4e, 0x91,
+	0x03, 0xea, 0xb2, 0x5a, 0xb8, 0x4a, 0x4f, 0x39, 0xb5, 0x30, 0x5b, 0xa2, 0x1f, 0xc0, 0x98, 0xa7,
+	0x24, 0x9b, 0x55, 0x6e, 0xa7, 0xa7, 0x9e, 0x76, 0x2f, 0x4e, 0xfa, 0x42, 0xec, 0x3f, 0x7b, 0xfb,
+	0xd7, 0x3c, 0xcd, 0xcf, 0x69, 0xb9, 0xc6, 0x72, 0xcf, 0xe1, 0x18, 0xba, 0x2d, 0x98, 0xc9, 0x3f,
+	0x92, 0x75, 0x23, 0xff, 0x48, 0xd6, 0xe8, 0x6b, 0xd0, 0x9f, 0xa6, 0x59, 0x4d, 0xdc, 0x4e, 0x4f,
+	0x39, 0xed, 0x5e, 0xec, 0x4b, 0x75, 0xbe, 0xe9, 0x67, 0x46, 0x60, 0xc1, 0x7f, 0xdf, 0xf9, 0x4e,
+	0xf1, 0xfe, 0x04, 0xf0, 0xcb, 0xb2, 0x28, 0xb7, 0x79, 0x3d, 0x02, 0x8b, 0xae, 0x57, 0x24, 0xce,
+	0xa7, 0x4b, 0x21, 0x68, 0x61, 0x93, 0x01, 0xc1, 0x74, 0x49, 0xd0, 0x01, 0xe8, 0x15, 0x9d, 0x26,
+	0x8f, 0xae, 0xca, 0x09, 0x11, 0xa0, 0x6f, 0xc0, 0x48, 0xa6, 0x75, 0x45, 0x2a, 0x57, 0xeb, 0xa9,
+	0x2d, 0x03, 0xbc, 0xce, 0x90, 0x31, 0x58, 0x26, 0x78, 0x0b, 0x80, 0x0d, 0xca, 0xe4, 0x66, 0x64,
+	0x45, 0x7f, 0xe7, 0xf5, 0x75, 0x2c, 0x82, 0xc6, 0x53, 0x67, 0x8b, 0x27, 0x75, 0x9b, 0x27, 0xad,
+	0xe5, 0xc9, 0xab, 0x60, 0xef, 0xbe, 0x5e, 0xad, 0x4a, 0x52, 0x55, 0x64, 0x36, 0x2c, 0xea, 0x9c,
+	0x22, 0x0f, 0xf4, 0x8c, 0x3c, 0x91, 0x8c, 0x57, 0xdb, 0xbd, 0xb0, 0xa5, 0xcb, 0x31, 0xc3, 0xb0,
+	0xa0, 0xd0, 0x57, 0xb0, 0x47, 0x58, 0x63, 0xe2, 0xb7, 0x3d, 0xf8, 0xc8, 0xe1, 0xa8, 0x55, 0x34,
+	0x61, 0xa2, 0xdc, 0x8d, 0x86, 0x45, 0xe0, 0x0d, 0xda, 0x45, 0x45, 0x83, 0xfb, 0x60, 0x70, 0xae,
+	0x72, 0x15, 0xde, 0x9b, 0x4f, 0x65, 0xd5, 0x37, 0xe6, 0xb0, 0xcc, 0xf2, 0x7c, 0xb0, 0x6f, 0xd9,
+	0x11, 0x1b, 0x16, 0x39, 0x25, 0xcf, 0xf4, 0xf5, 0xa7, 0x2b, 0x6f, 0x3e, 0xdd, 0x85, 0x9d, 0x44,
+	0xe4, 0x71, 0x97, 0x36, 0x6e, 0x42, 0xef, 0x0e, 0xba, 0x42, 0x66, 0x9a, 0x65, 0xa4, 0x44, 0x08,
+	0xb4, 0x79, 0x9a, 0x35, 0x02, 0x7c, 0xcd, 0xb0, 0x2c, 0xcd, 0xc5, 0xf7, 0xa9, 0x98, 0xaf, 0xd1,
+	0x21, 0x98, 0xf3, 0x3a, 0x4f, 0x68, 0x5a, 0xe4, 0x4d, 0x9f, 0x9b, 0xd8, 0xfb, 0x47, 0x05, 0xe0,
+	0x9a, 0xe2, 0x18, 0xee, 0x42, 0x27, 0x9d, 0x49, 0xc1, 0x4e, 0x3a, 0x43, 0x27, 0xb0, 0x4b, 0xd3,
+	0x25, 0x89, 0xeb, 0x3c, 0x7d, 0x8e, 0xf3, 0x8a, 0x24, 0x52, 0xd8, 0x66, 0xe8, 0x24, 0x4f, 0x9f,
+	0x83, 0x8a, 0x24, 0x9b, 0x19, 0xa8, 0xdb, 0x67, 0x50, 0xc0, 0x67, 0xf2, 0x33, 0x36, 0x53, 0x88,
+	0x69, 0x11, 0x37, 0x9f, 0x2a, 0x0e, 0xd9, 0xb9, 0xdc, 0xba, 0xb1, 0xd4, 0x97, 0x3d, 0x6b, 0x86,
+	0x14, 0x35, 0x4d, 0xe4, 0x2c, 0x76, 0x93, 0x2d, 0xf4, 0x7b, 0x43, 0xd7, 0xb7, 0x0c, 0x9d, 0x03,
+	0xae, 0xc1, 0x9b, 0x2d, 0x02, 0xf4, 0x25, 0x7c, 0xfc, 0xa3, 0x4c, 0x29, 0x29, 0xe3, 0xa2, 0xa6,
+	0xab, 0x9a, 0xba, 0x3b, 0x9c, 0xb5, 0x05, 0x18, 0x72, 0x0c, 0x9d, 0x83, 0x29, 0xcb, 0x57, 0xae,
+	0xc9, 0xfd, 0x7f, 0xd2, 0xf6, 0x2f, 0x9d, 0xe0, 0x97, 0x24, 0x74, 0xc6, 0xee, 0x14, 0x9b, 0x9d,
+	0x6b, 0xf1, 0x4b, 0x8d, 0x5e, 0xa5, 0x73, 0x06, 0xcb, 0x8c, 0xcd, 0x0d, 0x80, 0xd6, 0x0d, 0x38,
+	0xfc, 0x11, 0x8e, 0xff, 0xb7, 0x21, 0xef, 0x3c, 0x24, 0x07, 0xed, 0x87, 0xc4, 0x6e, 0xbf, 0x1a,
+	0x7f, 0x2b, 0x00, 0x9b, 0xf7, 0x04, 0x9d, 0x80, 0xc6, 0x7a, 0x25, 0x6f, 0x92, 0xd3, 0x7e, 0x70,
+	0x58, 0x31, 0xcc, 0x59, 0xf4, 0x05, 0xd8, 0x15, 0x2d, 0xd3, 0x7c, 0x11, 0x6f, 0x54, 0x2d, 0xdc,
+	0x15, 0x98, 0x10, 0x3a, 0x02, 0x2b, 0xcd, 0xa9, 0xe4, 0x55, 0x7e, 0x60, 0xcc, 0x34, 0xa7, 0x82,
+	0xfc, 0x1c, 0xba, 0xf3, 0xac, 0x98, 0x36, 0x34, 0xbb, 0xdf, 0x0a, 0x06, 0x0e, 0x89, 0x84, 0x63,
+	0x80, 0x87, 0xa2, 0xc8, 0x24, 0xcf, 0x66, 0x66, 0x62, 0x8b, 0x21, 0x2f, 0xfb, 0x1f, 0xd6, 0x94,
+	0x54, 0x92, 0x17, 0x53, 0x03, 0x0e, 0xf1, 0x84, 0xb3, 0x3b, 0xd0, 0xf9, 0xc9, 0x43, 0x26, 0x68,
+	0x41, 0x18, 0xf8, 0xce, 0x07, 0x64, 0x81, 0x7e, 0xe5, 0x5f, 0x4e, 0x6e, 0x1c, 0x85, 0x81, 0xa3,
+	0xe0, 0x3a, 0x74, 0x3a, 0x6c, 0xf5, 0xcb, 0x00, 0x07, 0x8e, 0xca, 0x68, 0x1f, 0xe3, 0x10, 0x3b,
+	0x1a, 0x5b, 0x5e, 0x0f, 0xa2, 0xc1, 0xd8, 0xd1, 0xd9, 0xf2, 0x76, 0x10, 0x8c, 0x86, 0x8e, 0x71,
+	0xf6, 0x2b, 0x58, 0x2f, 0x6d, 0x40, 0x00, 0xc6, 0x7d, 0x84, 0x47, 0xc1, 0x8d, 0xf3, 0x01, 0xed,
+	0x80, 0x3a, 0x0a, 0x22, 0x47, 0xe1, 0xfb, 0xc6, 0xe1, 0x20, 0x12, 0xba, 0x97, 0x61, 0x38, 0x16,
+	0xba, 0x97, 0xbf, 0x45, 0xfe, 0xbd, 0xa3, 0x31, 0x30, 0x1a, 0xfd, 0xe4, 0x3b, 0x3a, 0xb2, 0xc1,
+	0xbc, 0x9a, 0xe0, 0x41, 0x34, 0x0a, 0x03, 0xc7, 0x78, 0x30, 0xf8, 0xaf, 0xe7, 0xdb, 0x7f, 0x07,
+	0x00, 0xaa, 0x89, 0x89, 0x10, 0x89, 0x06, 0x00, 0x00,
 }
@@ -1,15 +1,39 @@
 package ledge
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/sirupsen/logrus"
 )
 
 var (
+	updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
 	//testWriter = io.Writer(os.Stdout)
 	testWriter        = ioutil.Discard
 	testSpecification = &Specification{
@@ -139,7 +163,7 @@ func TestFakeLogger(t *testing.T) {
 				Contexts: []Context{
 					TestContextBar{"one", 2},
 				},
-				Event: &UnstructuredEvent{"hello"},
+				Event: &UnstructuredEvent{"hello", nil},
 			},
 			&Entry{
 				ID:    "5",
@@ -148,19 +172,19 @@ func TestFakeLogger(t *testing.T) {
 				Contexts: []Context{
 					TestContextBar{"one", 2},
 				},
-				Event: &UnstructuredEvent{"{key:value} hello"},
+				Event: &UnstructuredEvent{"hello", map[string]*FieldValue{"key": NewFieldValue("value")}},
 			},
 			&Entry{
 				ID:    "6",
 				Time:  time.Unix(600, 0),
 				Level: Level_INFO,
-				Event: &UnstructuredEvent{"{key:value}"},
+				Event: &UnstructuredEvent{"", map[string]*FieldValue{"key": NewFieldValue("value")}},
 			},
 			&Entry{
 				ID:    "7",
 				Time:  time.Unix(700, 0),
 				Level: Level_INFO,
-				Event: &UnstructuredEvent{""},
+				Event: &UnstructuredEvent{"", nil},
 			},
 			&Entry{
 				ID:    "8",
@@ -169,7 +193,7 @@ func TestFakeLogger(t *testing.T) {
 				Contexts: []Context{
 					Level_PANIC,
 				},
-				Event: &UnstructuredEvent{"panic context"},
+				Event: &UnstructuredEvent{"panic context", nil},
 			},
 		},
 		true,
@@ -179,6 +203,94 @@ func TestFakeLogger(t *testing.T) {
 	}
 }
 
+func TestFatalAndPanic(t *testing.T) {
+	fakeLogger, err := NewFakeLogger(testSpecification)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, exited := fakeLogger.ExitCode(); exited {
+		t.Error("expected no exit")
+	}
+	fakeLogger.Fatal(TestEventFoo{"one", 2})
+	if code, exited := fakeLogger.ExitCode(); !exited || code != 1 {
+		t.Errorf("expected exit code 1, got %d %v", code, exited)
+	}
+	if err := fakeLogger.CheckEntriesEqual([]*Entry{&Entry{Level: Level_FATAL, Event: TestEventFoo{"one", 2}}}, false, false); err != nil {
+		t.Error(err)
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	bufferedWriter := bufio.NewWriter(buffer)
+	var exitCodes []int
+	var panicValues []interface{}
+	logger, err := NewLogger(
+		bufferedWriter,
+		ProtoMarshaller,
+		testSpecification,
+		LoggerOptions{
+			Encoder:   RPCEncoder,
+			Filters:   []Filter{NotFilter(DebugFilter)},
+			ExitFunc:  func(code int) { exitCodes = append(exitCodes, code) },
+			PanicFunc: func(value interface{}) { panicValues = append(panicValues, value) },
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info(TestEventFoo{"one", 2})
+	logger.Fatal(TestEventFoo{"two", 2})
+	if !reflect.DeepEqual(exitCodes, []int{1}) {
+		t.Errorf("expected exit code 1, got %v", exitCodes)
+	}
+	if buffer.Len() == 0 {
+		t.Error("expected the buffered writer to be flushed")
+	}
+	logger.Panic(&TestEventFooPtr{"three", 2})
+	if len(panicValues) != 1 {
+		t.Fatalf("expected 1 panic, got %d", len(panicValues))
+	}
+	entries := readTestEntries(t, bytes.NewReader(buffer.Bytes()))
+	if err := checkEntriesEqual(
+		entries,
+		[]*Entry{
+			&Entry{Level: Level_FATAL, Event: TestEventFoo{"two", 2}},
+			&Entry{Level: Level_PANIC, Event: &TestEventFooPtr{"three", 2}},
+		},
+		false,
+		false,
+	); err != nil {
+		t.Error(err)
+	}
+	unmarshaller, err := NewProtoUnmarshaller(testSpecification)
+	if err != nil {
+		t.Fatal(err)
+	}
+	panicEntry, err := unmarshaller.Unmarshal([]byte(panicValues[0].(string)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkEntriesEqual([]*Entry{panicEntry}, entries[1:], true, true); err != nil {
+		t.Error(err)
+	}
+
+	buffer.Reset()
+	logger, err = NewLogger(buffer, ProtoMarshaller, testSpecification, LoggerOptions{Encoder: RPCEncoder})
+	if err != nil {
+		t.Fatal(err)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic")
+			}
+		}()
+		logger.Panic(TestEventFoo{"four", 2})
+	}()
+	if entries := readTestEntries(t, buffer); len(entries) != 1 {
+		t.Errorf("expected panic entry to be written before panicking, got %d entries", len(entries))
+	}
+}
+
 func TestRoundTrip(t *testing.T) {
 	buffer := bytes.NewBuffer(nil)
 	timer := newFakeTimer(0)
@@ -272,7 +384,7 @@ func TestRoundTrip(t *testing.T) {
 				Contexts: []Context{
 					TestContextBar{"one", 2},
 				},
-				Event: &UnstructuredEvent{"hello"},
+				Event: &UnstructuredEvent{"hello", nil},
 			},
 			&Entry{
 				ID:    "5",
@@ -281,25 +393,93 @@ func TestRoundTrip(t *testing.T) {
 				Contexts: []Context{
 					TestContextBar{"one", 2},
 				},
-				Event: &UnstructuredEvent{"{key:value} hello"},
+				Event: &UnstructuredEvent{"hello", map[string]*FieldValue{"key": NewFieldValue("value")}},
 			},
 			&Entry{
 				ID:    "6",
 				Time:  time.Unix(600, 0),
 				Level: Level_INFO,
-				Event: &UnstructuredEvent{"{key:value}"},
+				Event: &UnstructuredEvent{"", map[string]*FieldValue{"key": NewFieldValue("value")}},
 			},
 			&Entry{
 				ID:    "7",
 				Time:  time.Unix(700, 0),
 				Level: Level_INFO,
-				Event: &UnstructuredEvent{""},
+				Event: &UnstructuredEvent{"", nil},
 			},
 			&Entry{
 				ID:    "8",
 				Time:  time.Unix(800, 0),
 				Level: Level_INFO,
-				Event: &UnstructuredEvent{byteString},
+				Event: &UnstructuredEvent{byteString, nil},
+			},
+		},
+		true,
+		true,
+	); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestProtoContextOrder(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger, err := NewLogger(
+		buffer,
+		ProtoMarshaller,
+		testSpecification,
+		LoggerOptions{
+			IDAllocator: newFakeIDAllocator(),
+			Timer:       newFakeTimer(0),
+			Encoder:     RPCEncoder,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.WithContext(TestRequestID("outer")).WithContext(TestInteger(10)).WithContext(TestRequestID("inner")).Info(TestEventFoo{"one", 2})
+	// a ProtoEntry as written before the ordered contexts field existed
+	data, err := proto.Marshal(
+		&ProtoEntry{
+			Id:    "1",
+			Level: Level_INFO,
+			ContextTypeNameToContext: map[string][]byte{
+				testTypeName(t, TestRequestID("bar")): testMarshalBinary(t, TestRequestID("bar")),
+				testTypeName(t, TestInteger(10)):      testMarshalBinary(t, TestInteger(10)),
+			},
+			EventTypeName: `*"github.com/codeship/go-ledge".UnstructuredEvent`,
+			Event:         testMarshalBinary(t, &UnstructuredEvent{"hello", nil}),
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RPCEncoder.Encode(buffer, []byte(base64.StdEncoding.EncodeToString(data))); err != nil {
+		t.Fatal(err)
+	}
+	entries := readTestEntries(t, buffer)
+	if err := checkEntriesEqual(
+		entries,
+		[]*Entry{
+			&Entry{
+				ID:    "0",
+				Time:  time.Unix(0, 0),
+				Level: Level_INFO,
+				Contexts: []Context{
+					TestRequestID("outer"),
+					TestInteger(10),
+					TestRequestID("inner"),
+				},
+				Event: TestEventFoo{"one", 2},
+			},
+			&Entry{
+				ID:    "1",
+				Time:  time.Unix(0, 0),
+				Level: Level_INFO,
+				Contexts: []Context{
+					TestInteger(10),
+					TestRequestID("bar"),
+				},
+				Event: &UnstructuredEvent{"hello", nil},
 			},
 		},
 		true,
@@ -308,3 +488,2322 @@ func TestRoundTrip(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func testTypeName(t *testing.T, object interface{}) string {
+	typeName, err := getReflectTypeName(reflect.TypeOf(object))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return typeName
+}
+
+func testMarshalBinary(t *testing.T, object interface{}) []byte {
+	data, err := protoMarshallerInstance.marshalBinary(object)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestCaller(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger, err := NewLogger(
+		buffer,
+		ProtoMarshaller,
+		testSpecification,
+		LoggerOptions{
+			Encoder:         RPCEncoder,
+			IncludeCaller:   true,
+			StackTraceLevel: Level_ERROR,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []int
+	addLine := func() {
+		_, _, line, _ := runtime.Caller(1)
+		lines = append(lines, line+1)
+	}
+	addLine()
+	logger.Info(TestEventFoo{"one", 2})
+	addLine()
+	logger.WithContext(TestRequestID("bar")).Unstructured().Info("hello")
+	addLine()
+	_, _ = logger.InfoWriter(TestEventFoo{"one", 2}).Write([]byte("output"))
+	addLine()
+	logger.Error(TestEventFoo{"one", 2})
+
+	entries := readTestEntries(t, buffer)
+	if len(entries) != len(lines) {
+		t.Fatalf("expected %d entries, got %d", len(lines), len(entries))
+	}
+	for i, entry := range entries {
+		if entry.Caller == nil {
+			t.Fatalf("expected caller for entry %d", i)
+		}
+		if filepath.Base(entry.Caller.File) != "ledge_test.go" || entry.Caller.Line != lines[i] || entry.Caller.Function != "github.com/codeship/go-ledge.TestCaller" {
+			t.Errorf("unexpected caller %s for entry %d, expected line %d", entry.Caller.String(), i, lines[i])
+		}
+		if hasStack := strings.Contains(entry.Stack, "TestCaller"); hasStack != (entry.Level == Level_ERROR) {
+			t.Errorf("unexpected stack for entry %d: %s", i, entry.Stack)
+		}
+	}
+
+	jsonData, err := JSONMarshaller.Marshal(entries[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	unmarshaller, err := NewJSONUnmarshaller(testSpecification, JSONUnmarshallerOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonEntry, err := unmarshaller.Unmarshal(jsonData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkEntriesEqual([]*Entry{jsonEntry}, []*Entry{entries[3]}, true, true); err != nil {
+		t.Error(err)
+	}
+	if jsonEntry.Stack != entries[3].Stack {
+		t.Errorf("expected stack %s, got %s", entries[3].Stack, jsonEntry.Stack)
+	}
+	textData, err := NewTextMarshaller(TextMarshallerOptions{NoID: true, NoTime: true}).Marshal(entries[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedPrefix := fmt.Sprintf("{level=error caller=%s TestEventFoo={One:one Two:2}}\ngoroutine ", shortCallerString(entries[3].Caller))
+	if !strings.HasPrefix(string(textData), expectedPrefix) {
+		t.Errorf("expected prefix %q, got %q", expectedPrefix, string(textData))
+	}
+
+	RegisterAdapterPackage("example.com/adapter")
+	for function, expected := range map[string]bool{
+		"example.com/adapter.Info":             true,
+		"example.com/adapter.(*logger).Info":   true,
+		"example.com/adapterfoo.Info":          false,
+		"example.com/adapter/sub.Info":         false,
+		"github.com/codeship/go-ledge.TestFoo": false,
+	} {
+		if isAdapterFunction(function) != expected {
+			t.Errorf("expected isAdapterFunction(%s) to be %v", function, expected)
+		}
+	}
+
+	// the caller and stack are only captured for Entry objects that are included
+	var filtered []*Entry
+	filteredLogger, err := NewLogger(
+		ioutil.Discard,
+		ProtoMarshaller,
+		testSpecification,
+		LoggerOptions{
+			Filters: []Filter{FilterFunc(func(entry *Entry) bool {
+				filtered = append(filtered, entry)
+				return false
+			})},
+			IncludeCaller:   true,
+			StackTraceLevel: Level_ERROR,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filteredLogger.Error(TestEventFoo{"one", 2})
+	if len(filtered) != 1 || filtered[0].Caller != nil || filtered[0].Stack != "" {
+		t.Errorf("expected no caller or stack for filtered entry, got %+v", filtered)
+	}
+}
+
+type testStackError struct {
+	msg string
+}
+
+func (e *testStackError) Error() string {
+	return e.msg
+}
+
+func (e *testStackError) StackTrace() []string {
+	return []string{"main.go:1", "main.go:2"}
+}
+
+// testNilStackError is usable as a typed nil error, but its StackTrace method is not
+type testNilStackError struct {
+	stack []string
+}
+
+func (e *testNilStackError) Error() string {
+	return "nil stack"
+}
+
+func (e *testNilStackError) StackTrace() []string {
+	return e.stack
+}
+
+type testPanicStackError struct{}
+
+func (e testPanicStackError) Error() string {
+	return "panic stack"
+}
+
+func (e testPanicStackError) StackTrace() []string {
+	panic("no stack")
+}
+
+func TestErrorEvent(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger, err := NewLogger(buffer, ProtoMarshaller, testSpecification, LoggerOptions{Encoder: RPCEncoder})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.ErrorEvent(fmt.Errorf("outer: %w", errors.Join(&testStackError{"inner"}, fmt.Errorf("eof: %w", io.EOF))))
+	logger.ErrorEvent(nil)
+	logger.ErrorEvent(fmt.Errorf("wrapped: %w", (*testNilStackError)(nil)))
+	logger.ErrorEvent(testPanicStackError{})
+	entries := readTestEntries(t, buffer)
+	expected := &ErrorEvent{
+		Msg:      "outer: inner\neof: EOF",
+		TypeName: "*fmt.wrapError",
+		Causes: []*ErrorCause{
+			&ErrorCause{Depth: 1, Msg: "inner\neof: EOF", TypeName: "*errors.joinError"},
+			&ErrorCause{Depth: 2, Msg: "inner", TypeName: "*ledge.testStackError", Stack: "[main.go:1 main.go:2]"},
+			&ErrorCause{Depth: 2, Msg: "eof: EOF", TypeName: "*fmt.wrapError"},
+			&ErrorCause{Depth: 3, Msg: "EOF", TypeName: "*errors.errorString"},
+		},
+	}
+	if err := checkEntriesEqual(
+		entries,
+		[]*Entry{
+			&Entry{Level: Level_ERROR, Event: expected},
+			&Entry{
+				Level: Level_ERROR,
+				Event: &ErrorEvent{
+					Msg:      "wrapped: nil stack",
+					TypeName: "*fmt.wrapError",
+					Causes:   []*ErrorCause{&ErrorCause{Depth: 1, Msg: "nil stack", TypeName: "*ledge.testNilStackError"}},
+				},
+			},
+			&Entry{Level: Level_ERROR, Event: &ErrorEvent{Msg: "panic stack", TypeName: "ledge.testPanicStackError"}},
+		},
+		false,
+		false,
+	); err != nil {
+		t.Fatal(err)
+	}
+	textData, err := NewTextMarshaller(TextMarshallerOptions{NoID: true, NoTime: true}).Marshal(entries[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedText := `{level=error ErrorEvent={"outer: inner\neof: EOF" (*fmt.wrapError) <- "inner\neof: EOF" (*errors.joinError) <- ` +
+		`["inner" (*ledge.testStackError), "eof: EOF" (*fmt.wrapError) <- "EOF" (*errors.errorString)]}}` +
+		"\n\"inner\" (*ledge.testStackError):\n[main.go:1 main.go:2]"
+	if string(textData) != expectedText {
+		t.Errorf("expected %s, got %s", expectedText, string(textData))
+	}
+}
+
+func TestUnstructuredFields(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger, err := NewLogger(buffer, ProtoMarshaller, testSpecification, LoggerOptions{Encoder: RPCEncoder})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fieldTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	logger.Unstructured().WithFields(
+		Fields{
+			"string":   "a b",
+			"int":      uint8(1),
+			"float":    1.5,
+			"bool":     true,
+			"bytes":    []byte("abc"),
+			"time":     fieldTime,
+			"duration": time.Second,
+			"error":    io.EOF,
+		},
+	).Info("hello")
+	entries := readTestEntries(t, buffer)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	fields := entries[0].Event.(*UnstructuredEvent).Fields
+	for key, expected := range map[string]interface{}{
+		"string":   "a b",
+		"int":      int64(1),
+		"float":    1.5,
+		"bool":     true,
+		"bytes":    []byte("abc"),
+		"time":     fieldTime,
+		"duration": time.Second,
+		"error":    "EOF",
+	} {
+		if value := fields[key].Value(); !reflect.DeepEqual(value, expected) {
+			t.Errorf("%s: expected %v, got %v", key, expected, value)
+		}
+	}
+
+	textData, err := NewTextMarshaller(TextMarshallerOptions{NoID: true, NoTime: true}).Marshal(entries[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedText := `{level=info UnstructuredEvent=msg:"hello" bool=true bytes=YWJj duration=1s error=EOF float=1.5 int=1 string="a b" time=2026-01-02T03:04:05Z}`
+	if string(textData) != expectedText {
+		t.Errorf("expected %s, got %s", expectedText, string(textData))
+	}
+	jsonData, err := JSONMarshaller.Marshal(entries[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedJSON := `"fields":{"bool":true,"bytes":"YWJj","duration":"1s","error":"EOF","float":1.5,"int":1,"string":"a b","time":"2026-01-02T03:04:05Z"}`; !strings.Contains(string(jsonData), expectedJSON) {
+		t.Errorf("expected %s in %s", expectedJSON, string(jsonData))
+	}
+	unmarshaller, err := NewJSONUnmarshaller(testSpecification, JSONUnmarshallerOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonEntry, err := unmarshaller.Unmarshal(jsonData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonFields := jsonEntry.Event.(*UnstructuredEvent).Fields
+	for _, key := range []string{"string", "int", "float", "bool", "error"} {
+		if !proto.Equal(jsonFields[key], fields[key]) {
+			t.Errorf("%s: expected %v, got %v", key, fields[key], jsonFields[key])
+		}
+	}
+
+	if !NewFieldFilter("int", 1).Include(entries[0]) || NewFieldFilter("int", "1").Include(entries[0]) {
+		t.Error("expected field filter to match by type and value")
+	}
+}
+
+func TestNonFiniteFloatFields(t *testing.T) {
+	for _, marshaller := range []Marshaller{
+		JSONMarshaller,
+		newJSONMarshaller(JSONMarshallerOptions{ContextsKey: "contexts"}),
+		ECSMarshaller,
+		NewGELFMarshaller(GELFMarshallerOptions{}),
+	} {
+		buffer := bytes.NewBuffer(nil)
+		logger, err := NewLogger(buffer, marshaller, testSpecification, LoggerOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.Unstructured().WithFields(
+			Fields{
+				"nan":    math.NaN(),
+				"posinf": math.Inf(1),
+				"neginf": math.Inf(-1),
+			},
+		).Info("x")
+		for _, expected := range []string{`"NaN"`, `"+Inf"`, `"-Inf"`} {
+			if !strings.Contains(buffer.String(), expected) {
+				t.Errorf("expected %s in %s", expected, buffer.String())
+			}
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	for _, fullyQualified := range []bool{false, true} {
+		marshaller := JSONMarshaller
+		if fullyQualified {
+			marshaller = FullyQualifiedJSONMarshaller
+		}
+		buffer := bytes.NewBuffer(nil)
+		timer := newFakeTimer(0)
+		logger, err := NewLogger(
+			buffer,
+			marshaller,
+			testSpecification,
+			LoggerOptions{
+				IDAllocator: newFakeIDAllocator(),
+				Timer:       timer,
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.Info(TestEventFoo{"one", 2})
+		timer.AddTimeSec(100)
+		logger.WithContext(TestRequestID("bar")).WithContext(TestInteger(10)).Warn(&TestEventFooPtr{"one", 2})
+		timer.AddTimeSec(100)
+		logger.WithContext(TestContextBar{"one", 2}).Unstructured().Error("hello")
+		timer.AddTimeSec(100)
+		_, _ = logger.InfoWriter(TestEventFoo{"one", 2}).Write([]byte("output"))
+
+		unmarshaller, err := NewJSONUnmarshaller(
+			testSpecification,
+			JSONUnmarshallerOptions{
+				MarshallerOptions: JSONMarshallerOptions{FullyQualifiedTypeNames: fullyQualified},
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entryReader, err := NewEntryReader(
+			buffer,
+			unmarshaller,
+			RPCDecoder,
+			EntryReaderOptions{},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries, err := NewBlockingEntryReader(entryReader).Entries()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := checkEntriesEqual(
+			entries,
+			[]*Entry{
+				&Entry{
+					ID:    "0",
+					Time:  time.Unix(0, 0),
+					Level: Level_INFO,
+					Event: TestEventFoo{"one", 2},
+				},
+				&Entry{
+					ID:    "1",
+					Time:  time.Unix(100, 0),
+					Level: Level_WARN,
+					Contexts: []Context{
+						TestInteger(10),
+						TestRequestID("bar"),
+					},
+					Event: &TestEventFooPtr{"one", 2},
+				},
+				&Entry{
+					ID:    "2",
+					Time:  time.Unix(200, 0),
+					Level: Level_ERROR,
+					Contexts: []Context{
+						TestContextBar{"one", 2},
+					},
+					Event: &UnstructuredEvent{"hello", nil},
+				},
+				&Entry{
+					ID:           "3",
+					Time:         time.Unix(300, 0),
+					Level:        Level_INFO,
+					Event:        TestEventFoo{"one", 2},
+					WriterOutput: []byte("output"),
+				},
+			},
+			true,
+			true,
+		); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestJSONMarshallerOptions(t *testing.T) {
+	entry := &Entry{
+		ID:    "1",
+		Time:  time.Unix(100, 5).UTC(),
+		Level: Level_WARN,
+		Contexts: []Context{
+			TestInteger(10),
+			TestRequestID("bar"),
+		},
+		Event: TestEventFoo{"one", 2},
+	}
+	options := JSONMarshallerOptions{
+		Keys: JSONKeys{
+			Time:  "@timestamp",
+			Level: "severity",
+		},
+		TimeFormat:            JSONTimeFormatRFC3339Nano,
+		UpperCaseLevel:        true,
+		ContextsKey:           "context",
+		OmitEmptyWriterOutput: true,
+	}
+	marshaller, err := NewJSONMarshaller(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := marshaller.Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"@timestamp":"1970-01-01T00:01:40.000000005Z","TestEventFoo":{"One":"one","Two":2},"context":{"TestInteger":10,"TestRequestID":"bar"},"event_type":"TestEventFoo","id":"1","severity":"WARN"}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, string(data))
+	}
+
+	for _, options := range []JSONMarshallerOptions{
+		options,
+		JSONMarshallerOptions{
+			Keys:                    JSONKeys{ID: "entry_id", EventType: "type", WriterOutput: "msg"},
+			TimeFormat:              JSONTimeFormatUnixNano,
+			FullyQualifiedTypeNames: true,
+		},
+		JSONMarshallerOptions{
+			TimeFormat:  JSONTimeFormatLayout,
+			TimeLayout:  time.RFC1123Z,
+			ContextsKey: "ctx",
+		},
+		JSONMarshallerOptions{
+			TimeFormat: JSONTimeFormatRFC3339,
+		},
+	} {
+		entry.Time = time.Unix(100, 0).UTC()
+		marshaller, err := NewJSONMarshaller(options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := marshaller.Marshal(entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		unmarshaller, err := NewJSONUnmarshaller(testSpecification, JSONUnmarshallerOptions{MarshallerOptions: options})
+		if err != nil {
+			t.Fatal(err)
+		}
+		unmarshalledEntry, err := unmarshaller.Unmarshal(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := checkEntriesEqual([]*Entry{unmarshalledEntry}, []*Entry{entry}, true, true); err != nil {
+			t.Errorf("%s: %v", string(data), err)
+		}
+	}
+	if data, err := newJSONMarshaller(JSONMarshallerOptions{TimeFormat: JSONTimeFormatRFC3339}).Marshal(entry); err != nil || !strings.Contains(string(data), `"time":"1970-01-01T00:01:40Z"`) {
+		t.Errorf("expected RFC 3339 time, got %s: %v", string(data), err)
+	}
+
+	for _, options := range []JSONMarshallerOptions{
+		JSONMarshallerOptions{TimeFormat: JSONTimeFormatLayout},
+		JSONMarshallerOptions{TimeFormat: JSONTimeFormat(100)},
+		JSONMarshallerOptions{Keys: JSONKeys{ID: "level"}},
+		JSONMarshallerOptions{Keys: JSONKeys{Time: "ts", Stack: "ts"}},
+		JSONMarshallerOptions{ContextsKey: "caller"},
+	} {
+		if _, err := NewJSONMarshaller(options); err == nil {
+			t.Errorf("expected error for %+v", options)
+		}
+		if _, err := NewJSONUnmarshaller(testSpecification, JSONUnmarshallerOptions{MarshallerOptions: options}); err == nil {
+			t.Errorf("expected unmarshaller error for %+v", options)
+		}
+	}
+}
+
+func TestLogfmtRoundTrip(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	timer := newFakeTimer(0)
+	logger, err := NewLogger(
+		buffer,
+		LogfmtMarshaller,
+		testSpecification,
+		LoggerOptions{
+			IDAllocator: newFakeIDAllocator(),
+			Timer:       timer,
+			Encoder:     RPCEncoder,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info(TestEventFoo{"one two", 2})
+	timer.AddTimeSec(100)
+	logger.WithContext(TestRequestID("12")).WithContext(TestInteger(10)).WithContext(TestRequestID("x=\"y\"")).Warn(&TestEventFooPtr{"true", 2})
+	timer.AddTimeSec(100)
+	logger.WithContext(TestContextBar{"one", 2}).WithContext(TestRequestID("abc")).Unstructured().WithFields(
+		Fields{
+			"string":        "123",
+			"int":           1,
+			"float":         2.0,
+			"bool":          false,
+			"level":         "field",
+			"user.id":       "abc",
+			"TestRequestID": 5,
+		},
+	).Error("hello\nworld")
+	timer.AddTimeSec(100)
+	_, _ = logger.InfoWriter(TestEventFoo{}).Write([]byte("output"))
+
+	expectedLine := `id="1" time=1970-01-01T00:01:40Z level=warn event_type=TestEventFooPtr TestEventFooPtr.one="true" TestEventFooPtr.two=2 TestRequestID="12" TestInteger=10 TestRequestID="x=\"y\""`
+	if lines := strings.Split(buffer.String(), "\n"); len(lines) != 5 || lines[1] != expectedLine {
+		t.Errorf("expected %s, got %s", expectedLine, buffer.String())
+	}
+	unmarshaller, err := NewLogfmtUnmarshaller(testSpecification, UnmarshallerOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entryReader, err := NewEntryReader(
+		buffer,
+		unmarshaller,
+		RPCDecoder,
+		EntryReaderOptions{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := NewBlockingEntryReader(entryReader).Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkEntriesEqual(
+		entries,
+		[]*Entry{
+			&Entry{
+				ID:    "0",
+				Time:  time.Unix(0, 0),
+				Level: Level_INFO,
+				Event: TestEventFoo{"one two", 2},
+			},
+			&Entry{
+				ID:    "1",
+				Time:  time.Unix(100, 0),
+				Level: Level_WARN,
+				Contexts: []Context{
+					TestRequestID("12"),
+					TestInteger(10),
+					TestRequestID("x=\"y\""),
+				},
+				Event: &TestEventFooPtr{"true", 2},
+			},
+			&Entry{
+				ID:    "2",
+				Time:  time.Unix(200, 0),
+				Level: Level_ERROR,
+				Contexts: []Context{
+					TestContextBar{"one", 2},
+					TestRequestID("abc"),
+				},
+				Event: &UnstructuredEvent{
+					"hello\nworld",
+					map[string]*FieldValue{
+						"string":        NewFieldValue("123"),
+						"int":           NewFieldValue(1),
+						"float":         NewFieldValue(2.0),
+						"bool":          NewFieldValue(false),
+						"level":         NewFieldValue("field"),
+						"user.id":       NewFieldValue("abc"),
+						"TestRequestID": NewFieldValue(5),
+					},
+				},
+			},
+			&Entry{
+				ID:           "3",
+				Time:         time.Unix(300, 0),
+				Level:        Level_INFO,
+				Event:        TestEventFoo{},
+				WriterOutput: []byte("output"),
+			},
+		},
+		true,
+		true,
+	); err != nil {
+		t.Error(err)
+	}
+
+	entry := &Entry{
+		Level:    Level_INFO,
+		Contexts: []Context{TestRequestID("bar"), &RawContext{"Unknown", RawFormatJSON, []byte(`{"a":[1,"b"],"c":{"d":"1"}}`)}},
+		Event:    &RawEvent{"UnknownEvent", RawFormatJSON, []byte(`{}`)},
+		Caller:   &Caller{"/foo/bar.go", 10, "foo.Bar"},
+		Stack:    "line one\nline two",
+	}
+	data, err := NewLogfmtMarshaller(TextMarshallerOptions{NoID: true, NoTime: true}).Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedData := `level=info event_type=UnknownEvent UnknownEvent={} TestRequestID=bar Unknown.a="[1,\"b\"]" Unknown.c.d="1" caller.file=/foo/bar.go caller.line=10 caller.function=foo.Bar stack="line one\nline two"`
+	if string(data) != expectedData {
+		t.Errorf("expected %s, got %s", expectedData, string(data))
+	}
+	if _, err := unmarshaller.Unmarshal(data); err == nil {
+		t.Error("expected error for unknown types")
+	}
+	lenientUnmarshaller, err := NewLogfmtUnmarshaller(testSpecification, UnmarshallerOptions{Lenient: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lenientEntry, err := lenientUnmarshaller.Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry.Contexts[1] = &RawContext{"Unknown", RawFormatJSON, []byte(`{"a":"[1,\"b\"]","c":{"d":"1"}}`)}
+	if !reflect.DeepEqual(lenientEntry, entry) {
+		t.Errorf("expected %+v, got %+v", entry, lenientEntry)
+	}
+
+	buffer.Reset()
+	logger, err = NewLogger(buffer, NewLogfmtMarshaller(TextMarshallerOptions{NoID: true, NoTime: true}), testSpecification, LoggerOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Unstructured().WithFields(Fields{"user id": 1, `a="b"`: 2, "": 3}).Info("x")
+	if expectedLine := `level=info event_type=UnstructuredEvent msg=x fields._=3 fields.a__b_=2 fields.user_id=1`; !strings.Contains(buffer.String(), expectedLine) {
+		t.Errorf("expected %s, got %s", expectedLine, buffer.String())
+	}
+}
+
+func TestGoldenMarshallers(t *testing.T) {
+	entries := []*Entry{
+		&Entry{
+			ID:    "0",
+			Time:  time.Unix(1, 1234567).UTC(),
+			Level: Level_INFO,
+			Contexts: []Context{
+				TestRequestID("bar"),
+				TestContextBar{"one", 2},
+			},
+			Event:  TestEventFoo{"one", 2},
+			Caller: &Caller{"/src/foo/bar.go", 10, "foo.Bar"},
+		},
+		&Entry{
+			ID:    "1",
+			Time:  time.Unix(2, 0).UTC(),
+			Level: Level_ERROR,
+			Event: &UnstructuredEvent{
+				"hello",
+				map[string]*FieldValue{
+					"string":   NewFieldValue("a b"),
+					"int":      NewFieldValue(1),
+					"float":    NewFieldValue(1.5),
+					"bool":     NewFieldValue(true),
+					"duration": NewFieldValue(time.Second),
+					"user.id":  NewFieldValue("abc"),
+					"id":       NewFieldValue(2),
+				},
+			},
+		},
+		&Entry{
+			ID:    "2",
+			Time:  time.Unix(3, 0).UTC(),
+			Level: Level_PANIC,
+			Event: &ErrorEvent{
+				Msg:      "failed: EOF",
+				TypeName: "*errors.errorString",
+				Stack:    "main.main()\n\t/src/main.go:5\n",
+				Causes:   []*ErrorCause{&ErrorCause{Depth: 1, Msg: "EOF", TypeName: "*errors.errorString"}},
+			},
+			Stack: "goroutine 1 [running]:\n",
+		},
+		&Entry{
+			ID:           "3",
+			Time:         time.Unix(4, 0).UTC(),
+			Level:        Level_DEBUG,
+			Event:        TestEventFoo{"", 0},
+			WriterOutput: []byte("output\n"),
+		},
+		&Entry{
+			ID:       "4",
+			Time:     time.Unix(5, 0).UTC(),
+			Level:    Level_WARN,
+			Contexts: []Context{&RawContext{"*\"github.com/foo/bar\".Baz", RawFormatProto, []byte("baz")}},
+			Event:    &RawEvent{"Unknown", RawFormatJSON, []byte(`{"a":{"b":[1,2]},"c":true}`)},
+		},
+	}
+	for _, golden := range []struct {
+		path       string
+		marshaller Marshaller
+	}{
+		{"testdata/ecs.golden", ECSMarshaller},
+		{"testdata/gelf.golden", NewGELFMarshaller(GELFMarshallerOptions{Host: "test-host"})},
+		{"testdata/syslog.golden", NewSyslogMarshaller(SyslogMarshallerOptions{Facility: SyslogFacilityLocal0, Hostname: "test-host", AppName: "test", ProcID: "1"})},
+	} {
+		buffer := bytes.NewBuffer(nil)
+		for _, entry := range entries {
+			data, err := golden.marshaller.Marshal(entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			buffer.Write(append(data, '\n'))
+		}
+		if *updateGolden {
+			if err := ioutil.WriteFile(golden.path, buffer.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		expected, err := ioutil.ReadFile(golden.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buffer.Bytes(), expected) {
+			t.Errorf("%s: expected\n%s\ngot\n%s", golden.path, string(expected), buffer.String())
+		}
+	}
+}
+
+func TestGELFReservedFields(t *testing.T) {
+	entry := &Entry{
+		ID:    "1",
+		Level: Level_INFO,
+		Event: &UnstructuredEvent{
+			"hello",
+			map[string]*FieldValue{
+				"entry_id":   NewFieldValue("a"),
+				"event_type": NewFieldValue("b"),
+				"file":       NewFieldValue("c"),
+				"line":       NewFieldValue("d"),
+				"function":   NewFieldValue("e"),
+				"fields.foo": NewFieldValue("f"),
+			},
+		},
+		Caller: &Caller{"/foo/bar.go", 10, "foo.Bar"},
+	}
+	data, err := NewGELFMarshaller(GELFMarshallerOptions{Host: "test-host"}).Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := make(map[string]interface{})
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	for key, expected := range map[string]interface{}{
+		"_entry_id":          "1",
+		"_event_type":        "UnstructuredEvent",
+		"_file":              "/foo/bar.go",
+		"_line":              10.0,
+		"_function":          "foo.Bar",
+		"_fields.entry_id":   "a",
+		"_fields.event_type": "b",
+		"_fields.file":       "c",
+		"_fields.line":       "d",
+		"_fields.function":   "e",
+		"_fields.fields.foo": "f",
+	} {
+		if m[key] != expected {
+			t.Errorf("%s: expected %v, got %v", key, expected, m[key])
+		}
+	}
+}
+
+func TestSyslogWriter(t *testing.T) {
+	dirPath, err := ioutil.TempDir("", "ledge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dirPath) }()
+	unixgramConn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: filepath.Join(dirPath, "syslog.sock"), Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = unixgramConn.Close() }()
+	tcpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = tcpListener.Close() }()
+	tcpData := make(chan []byte, 1)
+	go func() {
+		conn, err := tcpListener.Accept()
+		if err != nil {
+			tcpData <- nil
+			return
+		}
+		data, _ := ioutil.ReadAll(conn)
+		_ = conn.Close()
+		tcpData <- data
+	}()
+
+	expected := []string{
+		"<134>1 1970-01-01T00:00:00.000000Z test-host test 1 TestEventFoo [ledge@32473 TestRequestID=\"bar\"] {One:one Two:2}",
+		"<131>1 1970-01-01T00:01:40.000000Z test-host test 1 UnstructuredEvent - hello world",
+	}
+	for _, network := range []string{"unixgram", "tcp"} {
+		address := tcpListener.Addr().String()
+		if network == "unixgram" {
+			address = unixgramConn.LocalAddr().String()
+		}
+		writer, err := NewSyslogWriter(network, address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		timer := newFakeTimer(0)
+		logger, err := NewLogger(
+			writer,
+			NewSyslogMarshaller(SyslogMarshallerOptions{Facility: SyslogFacilityLocal0, Hostname: "test-host", AppName: "test", ProcID: "1"}),
+			testSpecification,
+			LoggerOptions{
+				IDAllocator: newFakeIDAllocator(),
+				Timer:       timer,
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.WithContext(TestRequestID("bar")).Info(TestEventFoo{"one", 2})
+		timer.AddTimeSec(100)
+		logger.Error(&UnstructuredEvent{Msg: "hello world"})
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+		var messages []string
+		if network == "unixgram" {
+			buffer := make([]byte, 1024)
+			for range expected {
+				n, err := unixgramConn.Read(buffer)
+				if err != nil {
+					t.Fatal(err)
+				}
+				messages = append(messages, string(buffer[:n]))
+			}
+		} else {
+			// messages on streams are framed as MSG-LEN SP SYSLOG-MSG
+			data := string(<-tcpData)
+			for data != "" {
+				i := strings.IndexByte(data, ' ')
+				if i < 0 {
+					t.Fatalf("invalid framing: %q", data)
+				}
+				length, err := strconv.Atoi(data[:i])
+				if err != nil || i+1+length > len(data) {
+					t.Fatalf("invalid framing: %q", data)
+				}
+				messages = append(messages, data[i+1:i+1+length])
+				data = data[i+1+length:]
+			}
+		}
+		if !reflect.DeepEqual(messages, expected) {
+			t.Errorf("%s: expected\n%s\ngot\n%s", network, strings.Join(expected, "\n"), strings.Join(messages, "\n"))
+		}
+		if _, err := writer.Write([]byte("closed\n")); err == nil {
+			t.Errorf("%s: expected error writing to closed writer", network)
+		}
+	}
+	if _, err := NewSyslogWriter("ip", "127.0.0.1"); err == nil {
+		t.Error("expected error for unsupported network")
+	}
+}
+
+func TestSyslogStructuredDataNames(t *testing.T) {
+	long := strings.Repeat("a", 30)
+	entry := &Entry{
+		Level: Level_INFO,
+		Contexts: []Context{
+			&RawContext{"Unknown", RawFormatJSON, []byte(fmt.Sprintf(`{"%s":{"x1":1,"x2":2,"x3":3}}`, long))},
+			TestRequestID("bar"),
+		},
+		Event: TestEventFoo{"one", 2},
+	}
+	data, err := NewSyslogMarshaller(SyslogMarshallerOptions{Hostname: "test-host", AppName: "test", ProcID: "1"}).Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := "Unknown." + long[:24]
+	expected := fmt.Sprintf(`[ledge@32473 %s="1" %s~2="2" %s~3="3" TestRequestID="bar"]`, name, name[:30], name[:30])
+	if !strings.Contains(string(data), expected) {
+		t.Errorf("expected %s in %s", expected, string(data))
+	}
+}
+
+type partialWriteConn struct {
+	net.Conn
+}
+
+func (p *partialWriteConn) Write(b []byte) (int, error) {
+	return len(b) / 2, errors.New("partial write")
+}
+
+func (p *partialWriteConn) Close() error {
+	return nil
+}
+
+func TestSyslogWriterPartialWrite(t *testing.T) {
+	tcpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = tcpListener.Close() }()
+	tcpData := make(chan []byte, 1)
+	go func() {
+		conn, err := tcpListener.Accept()
+		if err != nil {
+			tcpData <- nil
+			return
+		}
+		data, _ := ioutil.ReadAll(conn)
+		_ = conn.Close()
+		tcpData <- data
+	}()
+	writer := &syslogWriter{
+		"tcp",
+		tcpListener.Addr().String(),
+		true,
+		&partialWriteConn{},
+		false,
+		&sync.Mutex{},
+	}
+	if _, err := writer.Write([]byte("one\n")); err == nil {
+		t.Error("expected error for partial write")
+	}
+	if writer.conn != nil {
+		t.Error("expected connection to be dropped after partial write")
+	}
+	if _, err := writer.Write([]byte("two\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if data := string(<-tcpData); data != "3 two" {
+		t.Errorf("expected only the second message, got %q", data)
+	}
+
+	// a datagram too large to send fails after reconnecting as well
+	dirPath, err := ioutil.TempDir("", "ledge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dirPath) }()
+	unixgramConn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: filepath.Join(dirPath, "syslog.sock"), Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = unixgramConn.Close() }()
+	unixgramWriter, err := newSyslogWriter("unixgram", unixgramConn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := unixgramWriter.Write(bytes.Repeat([]byte("a"), 16<<20)); err == nil {
+		t.Error("expected error for datagram that is too large")
+	}
+	if unixgramWriter.conn != nil {
+		t.Error("expected connection to be dropped after failed write on reconnect")
+	}
+	if err := unixgramWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBinaryProtoRoundTrip(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	timer := newFakeTimer(0)
+	binaryLogger, err := NewLogger(
+		buffer,
+		BinaryProtoMarshaller,
+		testSpecification,
+		LoggerOptions{
+			IDAllocator: newFakeIDAllocator(),
+			Timer:       timer,
+			Encoder:     LengthDelimitedEncoder,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := NewLogger(
+		buffer,
+		ProtoMarshaller,
+		testSpecification,
+		LoggerOptions{
+			IDAllocator: newFakeIDAllocator(),
+			Timer:       timer,
+			Encoder:     RPCEncoder,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binaryLogger.WithContext(TestRequestID("bar")).Info(TestEventFoo{"one", 2})
+	timer.AddTimeSec(100)
+	binaryLogger.Unstructured().Info("hello\nworld")
+	timer.AddTimeSec(100)
+	// streams with both framings are read with RPCDecoder
+	logger.Info(TestEventFoo{"one", 2})
+	timer.AddTimeSec(100)
+	binaryLogger.Info(&TestEventFooPtr{"one", 2})
+	data := buffer.Bytes()
+
+	unmarshaller, err := NewProtoUnmarshaller(testSpecification)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entryReader, err := NewEntryReader(buffer, unmarshaller, RPCDecoder, EntryReaderOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := NewBlockingEntryReader(entryReader).Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkEntriesEqual(
+		entries,
+		[]*Entry{
+			&Entry{
+				ID:    "0",
+				Time:  time.Unix(0, 0),
+				Level: Level_INFO,
+				Contexts: []Context{
+					TestRequestID("bar"),
+				},
+				Event: TestEventFoo{"one", 2},
+			},
+			&Entry{
+				ID:    "1",
+				Time:  time.Unix(100, 0),
+				Level: Level_INFO,
+				Event: &UnstructuredEvent{"hello\nworld", nil},
+			},
+			&Entry{
+				ID:    "0",
+				Time:  time.Unix(200, 0),
+				Level: Level_INFO,
+				Event: TestEventFoo{"one", 2},
+			},
+			&Entry{
+				ID:    "2",
+				Time:  time.Unix(300, 0),
+				Level: Level_INFO,
+				Event: &TestEventFooPtr{"one", 2},
+			},
+		},
+		true,
+		true,
+	); err != nil {
+		t.Error(err)
+	}
+
+	bufReader := bufio.NewReader(bytes.NewReader(data))
+	for i := 0; i < 2; i++ {
+		if _, err := LengthDelimitedDecoder.Decode(bufReader); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := LengthDelimitedDecoder.Decode(bufReader); err == nil {
+		t.Error("expected error decoding newline framed data with LengthDelimitedDecoder")
+	}
+	if _, err := RPCDecoder.Decode(bufio.NewReader(bytes.NewReader(data[:10]))); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected %v for truncated frame, got %v", io.ErrUnexpectedEOF, err)
+	}
+
+	// binary data that only contains characters of base64 is still read as binary
+	id := strings.Repeat("a", '0')
+	binaryData, err := proto.Marshal(&ProtoEntry{Id: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	protoEntry, err := unmarshalProtoEntry(binaryData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if protoEntry.Id != id {
+		t.Errorf("expected id %s, got %s", id, protoEntry.Id)
+	}
+	if _, err := unmarshalProtoEntry([]byte("not base64 or proto\n")); err == nil {
+		t.Error("expected error for invalid data")
+	}
+}
+
+func TestRawPassthrough(t *testing.T) {
+	partialSpecification := &Specification{
+		ContextTypes: []Context{
+			TestRequestID(""),
+		},
+	}
+	for _, jsonFormat := range []bool{false, true} {
+		var marshaller Marshaller = ProtoMarshaller
+		var encoder Encoder = RPCEncoder
+		if jsonFormat {
+			marshaller = JSONMarshaller
+			encoder = nil
+		}
+		buffer := bytes.NewBuffer(nil)
+		logger, err := NewLogger(
+			buffer,
+			marshaller,
+			testSpecification,
+			LoggerOptions{
+				IDAllocator: newFakeIDAllocator(),
+				Timer:       newFakeTimer(0),
+				Encoder:     encoder,
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.WithContext(TestRequestID("foo")).WithContext(TestContextBar{"one", 2}).Warn(&TestEventFooPtr{"one", 2})
+		logger.Info(TestEventFoo{"one", 2})
+		data := buffer.Bytes()
+
+		newUnmarshaller := func(specification *Specification, lenient bool) Unmarshaller {
+			options := UnmarshallerOptions{Lenient: lenient}
+			var unmarshaller Unmarshaller
+			if jsonFormat {
+				unmarshaller, err = NewJSONUnmarshaller(specification, JSONUnmarshallerOptions{UnmarshallerOptions: options})
+			} else {
+				unmarshaller, err = NewProtoUnmarshallerWithOptions(specification, options)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			return unmarshaller
+		}
+		readEntries := func(data []byte, unmarshaller Unmarshaller) ([]*Entry, error) {
+			entryReader, err := NewEntryReader(bytes.NewReader(data), unmarshaller, RPCDecoder, EntryReaderOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return NewBlockingEntryReader(entryReader).Entries()
+		}
+
+		if _, err := readEntries(data, newUnmarshaller(partialSpecification, false)); err == nil {
+			t.Errorf("expected error for unknown types with strict unmarshaller, json=%v", jsonFormat)
+		}
+		rawEntries, err := readEntries(data, newUnmarshaller(partialSpecification, true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rawEntries) != 2 {
+			t.Fatalf("expected 2 entries, got %d", len(rawEntries))
+		}
+		rawEvent, ok := rawEntries[0].Event.(*RawEvent)
+		if !ok {
+			t.Fatalf("expected *RawEvent, got %T", rawEntries[0].Event)
+		}
+		if name := shortTypeName(rawEvent.TypeName); name != "TestEventFooPtr" {
+			t.Errorf("expected TestEventFooPtr, got %s", name)
+		}
+		numRawContexts := 0
+		for _, context := range rawEntries[0].Contexts {
+			if _, ok := context.(*RawContext); ok {
+				numRawContexts++
+			} else if context != TestRequestID("foo") {
+				t.Errorf("unexpected context %v", context)
+			}
+		}
+		if numRawContexts != 1 {
+			t.Errorf("expected 1 raw context, got %d", numRawContexts)
+		}
+		textData, err := NewTextMarshaller(TextMarshallerOptions{NoID: true, NoTime: true}).Marshal(rawEntries[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(textData, []byte("{level=info TestEventFoo=")) {
+			t.Errorf("unexpected text output %s", string(textData))
+		}
+
+		remarshalled := bytes.NewBuffer(nil)
+		for _, rawEntry := range rawEntries {
+			entryData, err := marshaller.Marshal(rawEntry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if encoder != nil {
+				if _, err := encoder.Encode(remarshalled, entryData); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				remarshalled.Write(append(entryData, '\n'))
+			}
+		}
+		if jsonFormat {
+			if !bytes.Equal(remarshalled.Bytes(), data) {
+				t.Errorf("expected %s, got %s", string(data), remarshalled.String())
+			}
+			continue
+		}
+		entries, err := readEntries(remarshalled.Bytes(), newUnmarshaller(testSpecification, false))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := checkEntriesEqual(
+			entries,
+			[]*Entry{
+				&Entry{
+					ID:    "0",
+					Time:  time.Unix(0, 0),
+					Level: Level_WARN,
+					Contexts: []Context{
+						TestRequestID("foo"),
+						TestContextBar{"one", 2},
+					},
+					Event: &TestEventFooPtr{"one", 2},
+				},
+				&Entry{
+					ID:    "1",
+					Time:  time.Unix(0, 0),
+					Level: Level_INFO,
+					Event: TestEventFoo{"one", 2},
+				},
+			},
+			true,
+			true,
+		); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestStdLoggerAndLogrusHook(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	logger, err := NewLogger(buffer, ProtoMarshaller, testSpecification, LoggerOptions{Encoder: RPCEncoder, IncludeCaller: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdLogger := NewStdLogger(logger.Unstructured(), Level_WARN, "app: ", log.LstdFlags|log.Lshortfile)
+	_, _, line, _ := runtime.Caller(0)
+	stdLogger.Printf("hello %d", 1)
+	stdLogger.SetFlags(log.Lmsgprefix)
+	stdLogger.Print("world")
+
+	logrusLogger := logrus.New()
+	logrusLogger.Out = ioutil.Discard
+	logrusLogger.Level = logrus.TraceLevel
+	logrusLogger.Hooks.Add(NewLogrusHook(logger.Unstructured()))
+	logrusLogger.WithField("foo", "bar").Trace("trace")
+	logrusLogger.WithFields(logrus.Fields{"one": 1, "two": "2"}).Error("error")
+
+	entries := readTestEntries(t, buffer)
+	for i, entry := range entries {
+		if entry.Caller == nil || entry.Caller.Function != "github.com/codeship/go-ledge.TestStdLoggerAndLogrusHook" {
+			t.Errorf("unexpected caller %v for entry %d", entry.Caller, i)
+		}
+		entry.Caller = nil
+	}
+	if err := checkEntriesEqual(
+		entries,
+		[]*Entry{
+			&Entry{Level: Level_WARN, Event: &UnstructuredEvent{"hello 1", map[string]*FieldValue{"file": NewFieldValue(fmt.Sprintf("ledge_test.go:%d", line+1)), "prefix": NewFieldValue("app: ")}}},
+			&Entry{Level: Level_WARN, Event: &UnstructuredEvent{"world", map[string]*FieldValue{"prefix": NewFieldValue("app: ")}}},
+			&Entry{Level: Level_DEBUG, Event: &UnstructuredEvent{"trace", map[string]*FieldValue{"foo": NewFieldValue("bar")}}},
+			&Entry{Level: Level_ERROR, Event: &UnstructuredEvent{"error", map[string]*FieldValue{"one": NewFieldValue(1), "two": NewFieldValue("2")}}},
+		},
+		false,
+		false,
+	); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGoContext(t *testing.T) {
+	fakeLogger, err := NewFakeLogger(testSpecification)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := AppendContexts(context.Background(), TestRequestID("abc"))
+	ctx = NewContext(ctx, fakeLogger.WithContext(TestInteger(1)))
+	childCtx := AppendContexts(ctx, TestContextBar{"one", 2})
+	siblingCtx := AppendContexts(ctx, Level_PANIC)
+	FromContext(childCtx).Info(TestEventFoo{"one", 2})
+	FromContext(siblingCtx).Info(TestEventFoo{"two", 2})
+	WithContextsFromContext(fakeLogger, context.Background()).Info(TestEventFoo{"three", 2})
+	if err := fakeLogger.CheckEntriesEqual(
+		[]*Entry{
+			&Entry{
+				Level:    Level_INFO,
+				Contexts: []Context{TestInteger(1), TestRequestID("abc"), TestContextBar{"one", 2}},
+				Event:    TestEventFoo{"one", 2},
+			},
+			&Entry{
+				Level:    Level_INFO,
+				Contexts: []Context{TestInteger(1), TestRequestID("abc"), Level_PANIC},
+				Event:    TestEventFoo{"two", 2},
+			},
+			&Entry{
+				Level: Level_INFO,
+				Event: TestEventFoo{"three", 2},
+			},
+		},
+		false,
+		false,
+	); err != nil {
+		t.Error(err)
+	}
+	if contexts := ContextsFromContext(childCtx); !reflect.DeepEqual(contexts, []Context{TestRequestID("abc"), TestContextBar{"one", 2}}) {
+		t.Errorf("unexpected contexts %v", contexts)
+	}
+	if logger := FromContext(context.Background()); logger != nil {
+		t.Errorf("expected no Logger without a global Logger, got %v", logger)
+	}
+}
+
+func TestAsyncLogger(t *testing.T) {
+	buffer := newLockedBuffer()
+	logger, err := NewAsyncLogger(
+		buffer,
+		ProtoMarshaller,
+		testSpecification,
+		LoggerOptions{
+			IDAllocator: newFakeIDAllocator(),
+			Timer:       newFakeTimer(0),
+			Encoder:     RPCEncoder,
+		},
+		AsyncLoggerOptions{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		logger.WithContext(TestInteger(i)).Info(TestEventFoo{"one", i})
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info(TestEventFoo{"closed", 0})
+	if dropped := logger.Dropped(); dropped != 1 {
+		t.Errorf("expected 1 dropped, got %d", dropped)
+	}
+	entries := readTestEntries(t, buffer)
+	if len(entries) != 100 {
+		t.Fatalf("expected 100 entries, got %d", len(entries))
+	}
+	for i, entry := range entries {
+		if entry.Event != (TestEventFoo{"one", i}) {
+			t.Errorf("expected %v, got %v", TestEventFoo{"one", i}, entry.Event)
+		}
+	}
+}
+
+func TestAsyncLoggerFatal(t *testing.T) {
+	// a bytes.Buffer is not safe for concurrent writes, so the race detector catches
+	// the Fatal Entry being written while the async goroutine writes
+	buffer := bytes.NewBuffer(nil)
+	var exitCodes []int
+	logger, err := NewAsyncLogger(
+		buffer,
+		ProtoMarshaller,
+		testSpecification,
+		LoggerOptions{
+			Encoder:  RPCEncoder,
+			ExitFunc: func(code int) { exitCodes = append(exitCodes, code) },
+		},
+		AsyncLoggerOptions{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				logger.Info(TestEventFoo{"concurrent", 0})
+			}
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		logger.Info(TestEventFoo{"before", i})
+	}
+	logger.Fatal(TestEventFoo{"fatal", 0})
+	close(stop)
+	<-stopped
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(exitCodes, []int{1}) {
+		t.Errorf("expected exit code 1, got %v", exitCodes)
+	}
+	before := 0
+	for _, entry := range readTestEntries(t, buffer) {
+		event := entry.Event.(TestEventFoo)
+		switch event.One {
+		case "before":
+			if event.Two != before {
+				t.Fatalf("expected %d, got %d", before, event.Two)
+			}
+			before++
+		case "fatal":
+			if before != 100 {
+				t.Fatalf("expected 100 entries before the Fatal entry, got %d", before)
+			}
+			return
+		}
+	}
+	t.Fatal("expected Fatal entry")
+}
+
+func TestAsyncLoggerPanicWriter(t *testing.T) {
+	writer := &testPanicWriter{newLockedBuffer()}
+	logger, err := NewAsyncLogger(writer, JSONMarshaller, testSpecification, LoggerOptions{}, AsyncLoggerOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	func() {
+		defer func() { _ = recover() }()
+		logger.Panic(TestEventFoo{"explode", 0})
+	}()
+	// the asyncWriter goroutine must not be left waiting on the write lock
+	done := make(chan error)
+	go func() {
+		logger.Info(TestEventFoo{"after", 0})
+		done <- logger.Close()
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected logger to be usable after a panic while writing")
+	}
+	output, err := ioutil.ReadAll(writer.buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(output, []byte(`"after"`)) || bytes.Contains(output, []byte(`"explode"`)) {
+		t.Errorf("expected only the entry after the panic, got %s", string(output))
+	}
+}
+
+type testPanicWriter struct {
+	buffer *lockedBuffer
+}
+
+func (w *testPanicWriter) Write(p []byte) (int, error) {
+	if bytes.Contains(p, []byte("explode")) {
+		panic("explode")
+	}
+	return w.buffer.Write(p)
+}
+
+func TestAsyncLoggerOverflow(t *testing.T) {
+	for overflowPolicy, expected := range map[OverflowPolicy][]int{
+		OverflowPolicyDropNewest: []int{0, 1},
+		OverflowPolicyDropOldest: []int{0, 3},
+	} {
+		writer := newTestBlockingWriter()
+		logger, err := NewAsyncLogger(
+			writer,
+			ProtoMarshaller,
+			testSpecification,
+			LoggerOptions{
+				Encoder: RPCEncoder,
+			},
+			AsyncLoggerOptions{
+				QueueSize:      1,
+				OverflowPolicy: overflowPolicy,
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.Info(TestEventFoo{"one", 0})
+		<-writer.started
+		for i := 1; i < 4; i++ {
+			logger.Info(TestEventFoo{"one", i})
+		}
+		if dropped := logger.Dropped(); dropped != 2 {
+			t.Errorf("expected 2 dropped, got %d", dropped)
+		}
+		if err := logger.Flush(10 * time.Millisecond); err == nil {
+			t.Error("expected flush timeout")
+		}
+		close(writer.release)
+		if err := logger.Flush(0); err != nil {
+			t.Fatal(err)
+		}
+		if err := logger.Close(); err != nil {
+			t.Fatal(err)
+		}
+		entries := readTestEntries(t, writer.buffer)
+		if len(entries) != len(expected) {
+			t.Fatalf("expected %d entries, got %d", len(expected), len(entries))
+		}
+		for i, entry := range entries {
+			if entry.Event != (TestEventFoo{"one", expected[i]}) {
+				t.Errorf("expected %v, got %v", TestEventFoo{"one", expected[i]}, entry.Event)
+			}
+		}
+	}
+}
+
+type testBlockingWriter struct {
+	buffer  *lockedBuffer
+	started chan struct{}
+	release chan struct{}
+	once    *sync.Once
+}
+
+func newTestBlockingWriter() *testBlockingWriter {
+	return &testBlockingWriter{
+		newLockedBuffer(),
+		make(chan struct{}),
+		make(chan struct{}),
+		&sync.Once{},
+	}
+}
+
+func (w *testBlockingWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.started) })
+	<-w.release
+	return w.buffer.Write(p)
+}
+
+func readTestEntries(t *testing.T, reader io.Reader) []*Entry {
+	unmarshaller, err := NewProtoUnmarshaller(testSpecification)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entryReader, err := NewEntryReader(
+		reader,
+		unmarshaller,
+		RPCDecoder,
+		EntryReaderOptions{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := NewBlockingEntryReader(entryReader).Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func TestRotatingFileWriter(t *testing.T) {
+	dirPath, err := ioutil.TempDir("", "ledge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dirPath) }()
+	path := filepath.Join(dirPath, "test.log")
+	timer := newFakeTimer(0)
+	writer, err := NewRotatingFileWriter(
+		path,
+		RotatingFileWriterOptions{
+			MaxSize:  1,
+			MaxFiles: 2,
+			Compress: true,
+			Timer:    timer,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger, err := NewLogger(
+		writer,
+		ProtoMarshaller,
+		testSpecification,
+		LoggerOptions{
+			IDAllocator: newFakeIDAllocator(),
+			Timer:       timer,
+			Encoder:     RPCEncoder,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		logger.Info(TestEventFoo{"one", i})
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	paths, err := RotatedFilePaths(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 3 {
+		t.Fatalf("expected 3 paths, got %v", paths)
+	}
+	reader, err := NewRotatedFileReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := readTestEntries(t, reader)
+	if err := reader.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	for i, entry := range entries {
+		if entry.Event != (TestEventFoo{"one", i + 7}) {
+			t.Errorf("expected %v, got %v", TestEventFoo{"one", i + 7}, entry.Event)
+		}
+	}
+}
+
+func TestRotatingFileWriterRotationFailure(t *testing.T) {
+	dirPath, err := ioutil.TempDir("", "ledge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dirPath) }()
+	path := filepath.Join(dirPath, "test.log")
+	timer := newFakeTimer(0)
+	writer, err := NewRotatingFileWriter(path, RotatingFileWriterOptions{Compress: true, Timer: timer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotatedPath := path + "." + timer.Now().UTC().Format(rotatedTimeFormat)
+	// a directory at the rotated path makes the rename fail
+	if err := os.Mkdir(rotatedPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writer.Write([]byte("one\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Rotate(); err == nil {
+		t.Error("expected error rotating to existing directory")
+	}
+	if _, err := writer.Write([]byte("two\n")); err != nil {
+		t.Fatalf("expected write after failed rotation to succeed, got %v", err)
+	}
+	if err := os.Remove(rotatedPath); err != nil {
+		t.Fatal(err)
+	}
+	// a directory at the compressed path makes compression fail after the rename
+	if err := os.Mkdir(rotatedPath+gzipSuffix, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Rotate(); err == nil {
+		t.Error("expected error compressing to existing file")
+	}
+	if _, err := writer.Write([]byte("three\n")); err != nil {
+		t.Fatalf("expected write after failed compression to succeed, got %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for path, expected := range map[string]string{
+		rotatedPath: "one\ntwo\n",
+		path:        "three\n",
+	} {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expected {
+			t.Errorf("%s: expected %q, got %q", path, expected, string(data))
+		}
+	}
+	if _, err := writer.Write([]byte("four\n")); err == nil {
+		t.Error("expected error writing to closed writer")
+	}
+
+	// rotating by size during a Write still writes to the file if rotation fails
+	backupBuffer := bytes.NewBuffer(nil)
+	path = filepath.Join(dirPath, "size.log")
+	writer, err = NewRotatingFileWriter(path, RotatingFileWriterOptions{MaxSize: 8, Timer: timer, BackupWriter: backupBuffer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(path+"."+timer.Now().UTC().Format(rotatedTimeFormat), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"one\n", "two\n", "three\n"} {
+		if n, err := writer.Write([]byte(line)); err != nil || n != len(line) {
+			t.Errorf("expected write of %q to succeed, got %d %v", line, n, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := ioutil.ReadFile(path); err != nil || string(data) != "one\ntwo\nthree\n" {
+		t.Errorf("expected all lines in %s, got %q %v", path, string(data), err)
+	}
+	if !strings.Contains(backupBuffer.String(), "ledge: failed to rotate") {
+		t.Errorf("expected rotation error in backup writer, got %q", backupBuffer.String())
+	}
+}
+
+func TestRotatingFileWriterIntervalAndMaxAge(t *testing.T) {
+	dirPath, err := ioutil.TempDir("", "ledge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dirPath) }()
+	path := filepath.Join(dirPath, "test.log")
+	timer := newFakeTimer(0)
+	writer, err := NewRotatingFileWriter(
+		path,
+		RotatingFileWriterOptions{
+			Interval: 10 * time.Second,
+			MaxAge:   15 * time.Second,
+			Timer:    timer,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expectedRotatedPaths []string
+	for i := 0; i < 5; i++ {
+		if i > 0 {
+			expectedRotatedPaths = append(expectedRotatedPaths, path+"."+timer.Now().UTC().Format(rotatedTimeFormat))
+		}
+		if _, err := writer.Write([]byte(fmt.Sprintf("%d\n", i))); err != nil {
+			t.Fatal(err)
+		}
+		// writes within the interval go to the same file
+		timer.AddTimeSec(5)
+		if _, err := writer.Write([]byte(fmt.Sprintf("%d\n", i))); err != nil {
+			t.Fatal(err)
+		}
+		timer.AddTimeSec(5)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	paths, err := RotatedFilePaths(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// files rotated at 10s and 20s are older than MaxAge at the rotation at 40s
+	expected := append(expectedRotatedPaths[2:], path)
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("expected %v, got %v", expected, paths)
+	}
+	for i, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := fmt.Sprintf("%d\n%d\n", i+2, i+2); string(data) != expected {
+			t.Errorf("%s: expected %q, got %q", path, expected, string(data))
+		}
+	}
+}
+
+func TestEntryReaderFollow(t *testing.T) {
+	dirPath, err := ioutil.TempDir("", "ledge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dirPath) }()
+	path := filepath.Join(dirPath, "test.log")
+	openLogger := func() (*os.File, Logger) {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger, err := NewLogger(file, ProtoMarshaller, testSpecification, LoggerOptions{Encoder: RPCEncoder})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return file, logger
+	}
+	file, logger := openLogger()
+	defer func() { _ = file.Close() }()
+	readFile, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = readFile.Close() }()
+	unmarshaller, err := NewProtoUnmarshaller(testSpecification)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entryReader, err := NewEntryReader(
+		readFile,
+		unmarshaller,
+		RPCDecoder,
+		EntryReaderOptions{
+			Follow:         true,
+			FollowInterval: 5 * time.Millisecond,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectEvent := func(expected Event) {
+		select {
+		case entryResponse := <-entryReader.Channel():
+			if entryResponse == nil || entryResponse.Error != nil || entryResponse.Entry.Event != expected {
+				t.Fatalf("expected %v, got %+v", expected, entryResponse)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %v", expected)
+		}
+	}
+
+	logger.Info(TestEventFoo{"one", 0})
+	expectEvent(TestEventFoo{"one", 0})
+
+	// rotate, with a final write to the old file after it was moved
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info(TestEventFoo{"one", 1})
+	newFile, newLogger := openLogger()
+	defer func() { _ = newFile.Close() }()
+	newLogger.Info(TestEventFoo{"one", 2})
+	expectEvent(TestEventFoo{"one", 1})
+	expectEvent(TestEventFoo{"one", 2})
+
+	if err := newFile.Truncate(0); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	newLogger.Info(TestEventFoo{"one", 3})
+	expectEvent(TestEventFoo{"one", 3})
+
+	if err := entryReader.Cancel(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case entryResponse, ok := <-entryReader.Channel():
+		if ok {
+			t.Fatalf("expected closed channel, got %+v", entryResponse)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for cancel")
+	}
+}
+
+func TestFollowReaderSeekedTruncation(t *testing.T) {
+	dirPath, err := ioutil.TempDir("", "ledge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dirPath) }()
+	path := filepath.Join(dirPath, "test.log")
+	if err := ioutil.WriteFile(path, []byte("hello world\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = file.Close() }()
+	// start at the end, as with tail -f
+	if _, err := file.Seek(0, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	cancel := make(chan struct{})
+	timer := time.AfterFunc(5*time.Second, func() { close(cancel) })
+	defer timer.Stop()
+	followReader := newFollowReader(file, 5*time.Millisecond, cancel)
+	if err := os.Truncate(path, 5); err != nil {
+		t.Fatal(err)
+	}
+	p := make([]byte, 64)
+	n, err := followReader.Read(p)
+	if err != nil || string(p[:n]) != "hello" {
+		t.Errorf("expected truncation to be detected, got %q %v", string(p[:n]), err)
+	}
+}
+
+func TestFilters(t *testing.T) {
+	entries := []*Entry{
+		&Entry{
+			ID:    "0",
+			Time:  time.Unix(0, 0),
+			Level: Level_ERROR,
+			Event: TestEventFoo{"one", 2},
+		},
+		&Entry{
+			ID:    "1",
+			Time:  time.Unix(100, 0),
+			Level: Level_INFO,
+			Contexts: []Context{
+				TestRequestID("bar"),
+			},
+			Event: &TestEventFooPtr{"one", 2},
+		},
+		&Entry{
+			ID:    "2",
+			Time:  time.Unix(200, 0),
+			Level: Level_DEBUG,
+			Contexts: []Context{
+				TestInteger(10),
+			},
+			Event:        TestEventFoo{"one", 2},
+			WriterOutput: []byte("hello world"),
+		},
+	}
+	for _, testCase := range []struct {
+		filter   Filter
+		expected []string
+	}{
+		{OrFilter(ErrorFilter, NewRequireContextFilter(TestRequestID("bar"))), []string{"0", "1"}},
+		{AndFilter(InfoFilter, NotFilter(ErrorFilter)), []string{"1"}},
+		{NewEventTypeFilter(TestEventFoo{}), []string{"0", "2"}},
+		{NewContextTypesFilter(TestRequestID(""), TestInteger(0)), []string{"1", "2"}},
+		{NewTimeRangeFilter(time.Unix(100, 0), time.Time{}), []string{"1", "2"}},
+		{NewTimeRangeFilter(time.Time{}, time.Unix(100, 0)), []string{"0"}},
+		{NewIDFilter("2"), []string{"2"}},
+		{NewWriterOutputFilter(regexp.MustCompile("^hello")), []string{"2"}},
+		{FilterFunc(func(entry *Entry) bool { return len(entry.Contexts) == 0 }), []string{"0"}},
+	} {
+		var ids []string
+		for _, entry := range entries {
+			if testCase.filter.Include(entry) {
+				ids = append(ids, entry.ID)
+			}
+		}
+		if !reflect.DeepEqual(ids, testCase.expected) {
+			t.Errorf("expected %v, got %v", testCase.expected, ids)
+		}
+	}
+}
+
+func TestSamplingFilters(t *testing.T) {
+	timer := newFakeTimer(0)
+	countIncluded := func(filter Filter, entry *Entry, n int) int {
+		included := 0
+		for i := 0; i < n; i++ {
+			if filter.Include(entry) {
+				included++
+			}
+		}
+		return included
+	}
+	infoEntry := &Entry{Level: Level_INFO, Event: TestEventFoo{"one", 2}}
+	ptrEntry := &Entry{Level: Level_INFO, Event: &TestEventFooPtr{"one", 2}}
+	suppressedEntry := &Entry{Level: Level_WARN, Event: &SuppressedEvent{}}
+
+	everyNFilter := NewEveryNFilter(3)
+	if included := countIncluded(everyNFilter, infoEntry, 7); included != 3 {
+		t.Errorf("expected 3 included, got %d", included)
+	}
+	if included := countIncluded(everyNFilter, ptrEntry, 2); included != 1 {
+		t.Errorf("expected 1 included, got %d", included)
+	}
+	if included := countIncluded(everyNFilter, suppressedEntry, 5); included != 5 {
+		t.Errorf("expected 5 included, got %d", included)
+	}
+
+	firstNFilter, err := NewFirstNFilter(2, 3, 10*time.Second, SamplingFilterOptions{Timer: timer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if included := countIncluded(firstNFilter, infoEntry, 7); included != 3 {
+		t.Errorf("expected 3 included, got %d", included)
+	}
+	timer.AddTimeSec(10)
+	if included := countIncluded(firstNFilter, infoEntry, 2); included != 2 {
+		t.Errorf("expected 2 included, got %d", included)
+	}
+	neverResetFilter, err := NewFirstNFilter(2, 0, 0, SamplingFilterOptions{Timer: timer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if included := countIncluded(neverResetFilter, infoEntry, 3); included != 2 {
+		t.Errorf("expected 2 included, got %d", included)
+	}
+	timer.AddTimeSec(1000)
+	if included := countIncluded(neverResetFilter, infoEntry, 3); included != 0 {
+		t.Errorf("expected 0 included, got %d", included)
+	}
+	if _, err := NewFirstNFilter(2, 0, -time.Second, SamplingFilterOptions{}); err == nil {
+		t.Error("expected error for negative interval")
+	}
+
+	rateLimitFilter := NewRateLimitFilter(1, 2, SamplingFilterOptions{Timer: timer})
+	barEntry := &Entry{Level: Level_INFO, Contexts: []Context{TestRequestID("bar")}, Event: TestEventFoo{"one", 2}}
+	bazEntry := &Entry{Level: Level_INFO, Contexts: []Context{TestRequestID("baz")}, Event: TestEventFoo{"one", 2}}
+	if included := countIncluded(rateLimitFilter, barEntry, 3); included != 2 {
+		t.Errorf("expected 2 included, got %d", included)
+	}
+	if included := countIncluded(rateLimitFilter, bazEntry, 1); included != 1 {
+		t.Errorf("expected 1 included, got %d", included)
+	}
+	timer.AddTimeSec(1)
+	if included := countIncluded(rateLimitFilter, barEntry, 2); included != 1 {
+		t.Errorf("expected 1 included, got %d", included)
+	}
+	// equal pointer Contexts share a bucket
+	ptrRateLimitFilter := NewRateLimitFilter(1, 1, SamplingFilterOptions{Timer: timer})
+	if included := countIncluded(ptrRateLimitFilter, &Entry{Level: Level_INFO, Contexts: []Context{&TestContextBar{"one", 2}}, Event: TestEventFoo{"one", 2}}, 1); included != 1 {
+		t.Errorf("expected 1 included, got %d", included)
+	}
+	if included := countIncluded(ptrRateLimitFilter, &Entry{Level: Level_INFO, Contexts: []Context{&TestContextBar{"one", 2}}, Event: TestEventFoo{"one", 2}}, 1); included != 0 {
+		t.Errorf("expected 0 included, got %d", included)
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	logger, err := NewLogger(buffer, ProtoMarshaller, testSpecification, LoggerOptions{Encoder: RPCEncoder})
+	if err != nil {
+		t.Fatal(err)
+	}
+	NewSuppressedReporter(logger, time.Hour, everyNFilter, firstNFilter, rateLimitFilter).Stop()
+	if err := checkEntriesEqual(
+		readTestEntries(t, buffer),
+		[]*Entry{
+			&Entry{
+				Level: Level_WARN,
+				Event: &SuppressedEvent{
+					Counts: []*SuppressedCount{
+						&SuppressedCount{Level_INFO, `"github.com/codeship/go-ledge".TestEventFoo`, 10},
+						&SuppressedCount{Level_INFO, `*"github.com/codeship/go-ledge".TestEventFooPtr`, 1},
+					},
+				},
+			},
+		},
+		false,
+		false,
+	); err != nil {
+		t.Error(err)
+	}
+	if suppressed := everyNFilter.Suppressed(); len(suppressed) != 0 {
+		t.Errorf("expected counts to be reset, got %v", suppressed)
+	}
+	// a non-positive interval falls back to the default instead of panicking
+	NewSuppressedReporter(logger, 0, ptrRateLimitFilter).Stop()
+}
+
+func TestAtomicLevel(t *testing.T) {
+	atomicLevel := NewAtomicLevel(Level_INFO)
+	debugEntry := &Entry{Level: Level_DEBUG, Event: TestEventFoo{"one", 2}}
+	if atomicLevel.Include(debugEntry) {
+		t.Error("expected debug entry to be excluded")
+	}
+	for _, testCase := range []struct {
+		method     string
+		body       string
+		statusCode int
+		response   string
+	}{
+		{"GET", "", http.StatusOK, `{"level":"info"}`},
+		{"PUT", `{"level":"debug"}`, http.StatusOK, `{"level":"debug"}`},
+		{"PUT", `{"level":"verbose"}`, http.StatusBadRequest, `{"error":"ledge: unknown level verbose"}`},
+		{"DELETE", "", http.StatusMethodNotAllowed, `{"error":"ledge: method DELETE not allowed"}`},
+	} {
+		recorder := httptest.NewRecorder()
+		atomicLevel.ServeHTTP(recorder, httptest.NewRequest(testCase.method, "/level", strings.NewReader(testCase.body)))
+		if recorder.Code != testCase.statusCode {
+			t.Errorf("%s %s: expected status %d, got %d", testCase.method, testCase.body, testCase.statusCode, recorder.Code)
+		}
+		if response := strings.TrimSpace(recorder.Body.String()); response != testCase.response {
+			t.Errorf("%s %s: expected %s, got %s", testCase.method, testCase.body, testCase.response, response)
+		}
+	}
+	if !atomicLevel.Include(debugEntry) {
+		t.Error("expected debug entry to be included")
+	}
+
+	levelRegistry := NewLevelRegistry(Level_INFO)
+	buffer := bytes.NewBuffer(nil)
+	logger, err := NewLogger(
+		buffer,
+		ProtoMarshaller,
+		testSpecification,
+		LoggerOptions{
+			IDAllocator:   newFakeIDAllocator(),
+			Timer:         newFakeTimer(0),
+			Encoder:       RPCEncoder,
+			LevelRegistry: levelRegistry,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbLogger := logger.Named("db")
+	logger.Debug(TestEventFoo{"root", 0})
+	dbLogger.Debug(TestEventFoo{"db", 0})
+	recorder := httptest.NewRecorder()
+	levelRegistry.ServeHTTP(recorder, httptest.NewRequest("PUT", "/levels", strings.NewReader(`{"name":"db","level":"debug"}`)))
+	if response := strings.TrimSpace(recorder.Body.String()); response != `{"levels":{"":"info","db":"debug"}}` {
+		t.Errorf("unexpected response %s", response)
+	}
+	logger.Debug(TestEventFoo{"root", 1})
+	dbLogger.Debug(TestEventFoo{"db", 1})
+	dbLogger.WithContext(TestRequestID("bar")).Debug(TestEventFoo{"db", 2})
+	dbLogger.Named("conn").Debug(TestEventFoo{"db.conn", 3})
+	if err := checkEntriesEqual(
+		readTestEntries(t, buffer),
+		[]*Entry{
+			&Entry{
+				Level: Level_DEBUG,
+				Event: TestEventFoo{"db", 1},
+			},
+			&Entry{
+				Level: Level_DEBUG,
+				Contexts: []Context{
+					TestRequestID("bar"),
+				},
+				Event: TestEventFoo{"db", 2},
+			},
+		},
+		false,
+		false,
+	); err != nil {
+		t.Error(err)
+	}
+	if levels := levelRegistry.Levels(); !reflect.DeepEqual(levels, map[string]Level{"": Level_INFO, "db": Level_DEBUG, "db.conn": Level_INFO}) {
+		t.Errorf("unexpected levels %v", levels)
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	entries := []*Entry{
+		&Entry{
+			ID:    "0",
+			Time:  time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+			Level: Level_ERROR,
+			Event: TestEventFoo{"one", 2},
+		},
+		&Entry{
+			ID:    "1",
+			Time:  time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+			Level: Level_WARN,
+			Contexts: []Context{
+				TestRequestID("abc"),
+				TestContextBar{"bar", 3},
+			},
+			Event: TestEventFoo{"one", 2},
+		},
+		&Entry{
+			ID:    "2",
+			Time:  time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC),
+			Level: Level_INFO,
+			Contexts: []Context{
+				TestRequestID("abc"),
+				Level_PANIC,
+			},
+			Event:        &TestEventFooPtr{"two", 4},
+			WriterOutput: []byte("hello"),
+		},
+		&Entry{
+			ID:    "3",
+			Time:  time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC),
+			Level: Level_INFO,
+			Event: &UnstructuredEvent{
+				"hello",
+				map[string]*FieldValue{
+					"user":    NewFieldValue("alice"),
+					"latency": NewFieldValue(1500 * time.Millisecond),
+					"count":   NewFieldValue(3),
+					"ok":      NewFieldValue(true),
+					"name":    NewFieldValue("Å"),
+				},
+			},
+		},
+	}
+	for expr, expected := range map[string][]string{
+		`level>=warn && event=TestEventFoo && ctx.TestRequestID=="abc" && time>"2026-01-01"`:             []string{"1"},
+		`level == error || ctx.TestContextBar.Two > 2`:                                                   []string{"0", "1"},
+		`!(ctx.TestRequestID) || event.One != one`:                                                       []string{"0", "2", "3"},
+		`ctx.TestContextBar.One =~ '^b' && ctx.TestContextBar.Two <= 3`:                                  []string{"1"},
+		`event.Two >= 4 && ctx.Level == panic`:                                                           []string{"2"},
+		`id != 1 && output !~ "^h"`:                                                                      []string{"0", "3"},
+		`field.user == alice && field.latency > 1s && field.count >= 3 && field.ok == true`:              []string{"3"},
+		`field.user =~ "^b" || field.missing || field.count < 3`:                                         nil,
+		`field.ok && level == info`:                                                                      []string{"3"},
+		`field.name == Å && field.name != ÅÅ`:                                                            []string{"3"},
+		`time <= "2026-01-03T00:00:00Z" && event == "*\"github.com/codeship/go-ledge\".TestEventFooPtr"`: []string{"2"},
+	} {
+		filter, err := ParseFilter(testSpecification, expr)
+		if err != nil {
+			t.Errorf("%s: %v", expr, err)
+			continue
+		}
+		var ids []string
+		for _, entry := range entries {
+			if filter.Include(entry) {
+				ids = append(ids, entry.ID)
+			}
+		}
+		if !reflect.DeepEqual(ids, expected) {
+			t.Errorf("%s: expected %v, got %v", expr, expected, ids)
+		}
+	}
+	for expr, position := range map[string]int{
+		`level >= warn &&`:             16,
+		`level >= loud`:                9,
+		`ctx.Unknown == "a"`:           4,
+		`ctx.TestContextBar.Three > 1`: 0,
+		`(level == warn`:               14,
+		`id == "abc`:                   6,
+		`level & id`:                   6,
+		`ctx.TestRequestID.Foo == "x"`: 0,
+		`ctx.TestInteger.X > 1`:        0,
+		`ctx.TestContextBar.Two.X > 1`: 0,
+		`event.Two > abc`:              12,
+		`event.Missing == 1`:           0,
+		`field.count =~ "("`:           15,
+	} {
+		_, err := ParseFilter(testSpecification, expr)
+		filterParseError, ok := err.(*FilterParseError)
+		if !ok {
+			t.Errorf("%s: expected FilterParseError, got %v", expr, err)
+			continue
+		}
+		if filterParseError.Position != position {
+			t.Errorf("%s: expected position %d, got %v", expr, position, err)
+		}
+	}
+
+	specification := &Specification{ContextTypes: []Context{TestContextUnexported{}}}
+	for _, expr := range []string{
+		`ctx.TestContextUnexported.inner == "x"`,
+		`ctx.TestContextUnexported.at > "2026-01-01"`,
+	} {
+		if _, err := ParseFilter(specification, expr); err == nil {
+			t.Errorf("%s: expected error for unexported field", expr)
+		} else if _, ok := err.(*FilterParseError); !ok {
+			t.Errorf("%s: expected FilterParseError, got %v", expr, err)
+		}
+	}
+}
+
+type TestContextUnexported struct {
+	Name  string
+	inner string
+	at    time.Time
+}
+
+func BenchmarkProtoMarshaller(b *testing.B) {
+	benchmarkMarshallerEncoder(b, ProtoMarshaller, RPCEncoder)
+}
+
+func BenchmarkBinaryProtoMarshaller(b *testing.B) {
+	benchmarkMarshallerEncoder(b, BinaryProtoMarshaller, LengthDelimitedEncoder)
+}
+
+func BenchmarkProtoUnmarshaller(b *testing.B) {
+	benchmarkUnmarshallerDecoder(b, ProtoMarshaller, RPCEncoder)
+}
+
+func BenchmarkBinaryProtoUnmarshaller(b *testing.B) {
+	benchmarkUnmarshallerDecoder(b, BinaryProtoMarshaller, LengthDelimitedEncoder)
+}
+
+func benchmarkMarshallerEncoder(b *testing.B, marshaller Marshaller, encoder Encoder) {
+	entry := newBenchmarkEntry()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		data, err := marshaller.Marshal(entry)
+		if err != nil {
+			b.Fatal(err)
+		}
+		n, err := encoder.Encode(ioutil.Discard, data)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.SetBytes(int64(n))
+	}
+}
+
+func benchmarkUnmarshallerDecoder(b *testing.B, marshaller Marshaller, encoder Encoder) {
+	data, err := marshaller.Marshal(newBenchmarkEntry())
+	if err != nil {
+		b.Fatal(err)
+	}
+	buffer := bytes.NewBuffer(nil)
+	if _, err := encoder.Encode(buffer, data); err != nil {
+		b.Fatal(err)
+	}
+	encoded := buffer.Bytes()
+	unmarshaller, err := NewProtoUnmarshaller(testSpecification)
+	if err != nil {
+		b.Fatal(err)
+	}
+	reader := bytes.NewReader(encoded)
+	bufReader := bufio.NewReader(reader)
+	b.SetBytes(int64(len(encoded)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		reader.Reset(encoded)
+		bufReader.Reset(reader)
+		decoded, err := RPCDecoder.Decode(bufReader)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := unmarshaller.Unmarshal(decoded); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func newBenchmarkEntry() *Entry {
+	return &Entry{
+		ID:       "4f6d5a3c-2b1e-4d0f-9a8b-7c6d5e4f3a2b",
+		Time:     time.Unix(1, 1234567).UTC(),
+		Level:    Level_INFO,
+		Contexts: []Context{TestRequestID("bar"), TestContextBar{"one", 2}},
+		Event:    TestEventFoo{"one", 2},
+		Caller:   &Caller{"/src/foo/bar.go", 10, "foo.Bar"},
+	}
+}
@@ -0,0 +1,425 @@
+/*
+Package ledgecmd implements the ledge command, which reads streams of marshalled Entry
+objects and writes them back out, optionally converted to another format and filtered.
+
+	ledge cat [flags] [files...]     re-marshal every Entry
+	ledge filter [flags] [files...]  re-marshal every Entry selected by the filter flags
+	ledge count [flags] [files...]   count Entry objects per Level and Event type
+
+If no files are given, standard input is read. Multiple files are read in order, and files
+ending in .gz are decompressed, so a set of files rotated by a RotatingFileWriter can be
+read with:
+
+	ledge cat $(ls app.log.* | sort) app.log
+
+Context and Event types have to be registered to decode Entry objects. Types are registered
+with ledge.RegisterSpecification, usually from an init function. The ledge command can load Go
+plugins that register types with the -plugin flag, or a binary that knows about the types can
+be built by importing the packages that register them and calling Main:
+
+	package main
+
+	import (
+		"github.com/codeship/go-ledge/ledgecmd"
+
+		_ "example.com/app/logtypes"
+	)
+
+	func main() {
+		ledgecmd.Main()
+	}
+
+Context and Event types that are not registered are read as ledge.RawContext and
+ledge.RawEvent values, and are printed with their raw type names and data.
+*/
+package ledgecmd
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"plugin"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/codeship/go-ledge"
+)
+
+const (
+	protoFormat       = "proto"
+	binaryProtoFormat = "proto-binary"
+)
+
+var (
+	inputFormats = map[string]func(*ledge.Specification) (ledge.Unmarshaller, error){
+		protoFormat: func(specification *ledge.Specification) (ledge.Unmarshaller, error) {
+			return ledge.NewProtoUnmarshallerWithOptions(specification, ledge.UnmarshallerOptions{Lenient: true})
+		},
+		"json": func(specification *ledge.Specification) (ledge.Unmarshaller, error) {
+			return ledge.NewJSONUnmarshaller(
+				specification,
+				ledge.JSONUnmarshallerOptions{
+					UnmarshallerOptions: ledge.UnmarshallerOptions{Lenient: true},
+				},
+			)
+		},
+		"json-fq": func(specification *ledge.Specification) (ledge.Unmarshaller, error) {
+			return ledge.NewJSONUnmarshaller(
+				specification,
+				ledge.JSONUnmarshallerOptions{
+					UnmarshallerOptions: ledge.UnmarshallerOptions{Lenient: true},
+					MarshallerOptions:   ledge.JSONMarshallerOptions{FullyQualifiedTypeNames: true},
+				},
+			)
+		},
+		"logfmt": func(specification *ledge.Specification) (ledge.Unmarshaller, error) {
+			return ledge.NewLogfmtUnmarshaller(specification, ledge.UnmarshallerOptions{Lenient: true})
+		},
+	}
+	outputFormats = map[string]func() ledge.Marshaller{
+		"text": func() ledge.Marshaller {
+			return ledge.NewTextMarshaller(ledge.TextMarshallerOptions{})
+		},
+		"text-v2": func() ledge.Marshaller {
+			return ledge.NewTextMarshallerV2(ledge.TextMarshallerOptions{})
+		},
+		"text-v3": func() ledge.Marshaller {
+			return ledge.NewTextMarshallerV3(0, ledge.TextMarshallerOptions{})
+		},
+		"logrus": func() ledge.Marshaller {
+			return ledge.NewLogrusTextMarshaller(ledge.TextMarshallerOptions{})
+		},
+		"json": func() ledge.Marshaller {
+			return ledge.JSONMarshaller
+		},
+		"json-fq": func() ledge.Marshaller {
+			return ledge.FullyQualifiedJSONMarshaller
+		},
+		"logfmt": func() ledge.Marshaller {
+			return ledge.LogfmtMarshaller
+		},
+		"ecs": func() ledge.Marshaller {
+			return ledge.ECSMarshaller
+		},
+		"gelf": func() ledge.Marshaller {
+			return ledge.NewGELFMarshaller(ledge.GELFMarshallerOptions{})
+		},
+		"syslog": func() ledge.Marshaller {
+			return ledge.NewSyslogMarshaller(ledge.SyslogMarshallerOptions{})
+		},
+		protoFormat: func() ledge.Marshaller {
+			return ledge.ProtoMarshaller
+		},
+		binaryProtoFormat: func() ledge.Marshaller {
+			return ledge.BinaryProtoMarshaller
+		},
+	}
+	commands = map[string]*command{
+		"cat": &command{
+			"re-marshal every Entry",
+			false,
+			runCat,
+		},
+		"filter": &command{
+			"re-marshal every Entry selected by the filter flags",
+			true,
+			runCat,
+		},
+		"count": &command{
+			"count Entry objects per Level and Event type",
+			true,
+			runCount,
+		},
+	}
+)
+
+// Main runs the ledge command with the arguments of the process, and exits.
+func Main() {
+	os.Exit(Run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// Run runs the ledge command with the given arguments, not including the program
+// name, and returns the exit code.
+func Run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return 2
+	}
+	command, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "ledge: unknown command %s\n", args[0])
+		printUsage(stderr)
+		return 2
+	}
+	flagSet := flag.NewFlagSet("ledge "+args[0], flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	options := newOptions(flagSet, command.filterFlags)
+	if err := flagSet.Parse(args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	bufferedStdout := bufio.NewWriter(stdout)
+	err := command.run(options, flagSet.Args(), stdin, bufferedStdout, stderr)
+	if flushErr := bufferedStdout.Flush(); flushErr != nil && err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return 1
+	}
+	return 0
+}
+
+type command struct {
+	description string
+	filterFlags bool
+	run         func(*options, []string, io.Reader, io.Writer, io.Writer) error
+}
+
+func printUsage(writer io.Writer) {
+	fmt.Fprintln(writer, "usage: ledge command [flags] [files...]")
+	fmt.Fprintln(writer)
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(writer, "\t%-8s%s\n", name, commands[name].description)
+	}
+	fmt.Fprintln(writer)
+	fmt.Fprintln(writer, "Run ledge command -h for the flags of a command.")
+}
+
+type options struct {
+	inFormat  string
+	outFormat string
+	plugins   stringSlice
+	level     string
+	contexts  stringSlice
+	event     string
+	expr      string
+}
+
+func newOptions(flagSet *flag.FlagSet, filterFlags bool) *options {
+	options := &options{}
+	flagSet.StringVar(&options.inFormat, "in", protoFormat, "input format, one of "+formatNames(inputFormats))
+	flagSet.StringVar(&options.outFormat, "out", "text", "output format, one of "+formatNames(outputFormats))
+	flagSet.Var(&options.plugins, "plugin", "Go plugin to load that registers Specifications, may be repeated")
+	if filterFlags {
+		flagSet.StringVar(&options.level, "level", "", "only select Entry objects at or above this level")
+		flagSet.Var(&options.contexts, "context", "only select Entry objects with a Context of this type, may be repeated")
+		flagSet.StringVar(&options.event, "event", "", "only select Entry objects with an Event of this type")
+		flagSet.StringVar(&options.expr, "expr", "", "only select Entry objects matching this ledge.ParseFilter expression")
+	}
+	return options
+}
+
+func formatNames(formats interface{}) string {
+	var names []string
+	switch formats := formats.(type) {
+	case map[string]func(*ledge.Specification) (ledge.Unmarshaller, error):
+		for name := range formats {
+			names = append(names, name)
+		}
+	case map[string]func() ledge.Marshaller:
+		for name := range formats {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func (o *options) getSpecification() (*ledge.Specification, error) {
+	for _, path := range o.plugins {
+		if _, err := plugin.Open(path); err != nil {
+			return nil, err
+		}
+	}
+	return ledge.RegisteredSpecification(), nil
+}
+
+func (o *options) getFilters(specification *ledge.Specification) ([]ledge.Filter, error) {
+	var exprs []string
+	if o.level != "" {
+		exprs = append(exprs, "level>="+strconv.Quote(o.level))
+	}
+	for _, context := range o.contexts {
+		exprs = append(exprs, "ctx."+context)
+	}
+	if o.event != "" {
+		exprs = append(exprs, "event=="+strconv.Quote(o.event))
+	}
+	if o.expr != "" {
+		exprs = append(exprs, o.expr)
+	}
+	var filters []ledge.Filter
+	for _, expr := range exprs {
+		filter, err := ledge.ParseFilter(specification, expr)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+// read calls entryFunc for every Entry read. Read errors are printed to stderr,
+// and an error is returned at the end if there were any.
+func (o *options) read(
+	args []string,
+	stdin io.Reader,
+	stderr io.Writer,
+	entryFunc func(*ledge.Entry) error,
+) error {
+	specification, err := o.getSpecification()
+	if err != nil {
+		return err
+	}
+	newUnmarshaller, ok := inputFormats[o.inFormat]
+	if !ok {
+		return fmt.Errorf("ledge: unknown input format %s", o.inFormat)
+	}
+	unmarshaller, err := newUnmarshaller(specification)
+	if err != nil {
+		return err
+	}
+	filters, err := o.getFilters(specification)
+	if err != nil {
+		return err
+	}
+	reader := stdin
+	if len(args) > 0 {
+		multiFileReader := ledge.NewMultiFileReader(args...)
+		defer func() { _ = multiFileReader.Close() }()
+		reader = multiFileReader
+	}
+	entryReader, err := ledge.NewEntryReader(
+		reader,
+		unmarshaller,
+		ledge.RPCDecoder,
+		ledge.EntryReaderOptions{
+			Filters: filters,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		// the files are only closed once the entry reader has stopped reading them,
+		// which it does after Cancel once the Read in progress returns
+		defer func() {
+			for range entryReader.Channel() {
+			}
+		}()
+	}
+	numErrors := 0
+	for entryResponse := range entryReader.Channel() {
+		if entryResponse.Error != nil {
+			numErrors++
+			fmt.Fprintln(stderr, entryResponse.Error.Error())
+			continue
+		}
+		if err := entryFunc(entryResponse.Entry); err != nil {
+			_ = entryReader.Cancel()
+			return err
+		}
+	}
+	if numErrors > 0 {
+		return fmt.Errorf("ledge: %d errors reading entries", numErrors)
+	}
+	return nil
+}
+
+func runCat(options *options, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	newMarshaller, ok := outputFormats[options.outFormat]
+	if !ok {
+		return fmt.Errorf("ledge: unknown output format %s", options.outFormat)
+	}
+	marshaller := newMarshaller()
+	return options.read(
+		args,
+		stdin,
+		stderr,
+		func(entry *ledge.Entry) error {
+			data, err := marshaller.Marshal(entry)
+			if err != nil {
+				return err
+			}
+			if options.outFormat == protoFormat {
+				_, err = ledge.RPCEncoder.Encode(stdout, data)
+				return err
+			}
+			if options.outFormat == binaryProtoFormat {
+				_, err = ledge.LengthDelimitedEncoder.Encode(stdout, data)
+				return err
+			}
+			_, err = stdout.Write(append(data, '\n'))
+			return err
+		},
+	)
+}
+
+func runCount(options *options, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	levelToCount := make(map[ledge.Level]int)
+	eventTypeToCount := make(map[string]int)
+	total := 0
+	if err := options.read(
+		args,
+		stdin,
+		stderr,
+		func(entry *ledge.Entry) error {
+			total++
+			levelToCount[entry.Level]++
+			eventTypeToCount[eventTypeName(entry.Event)]++
+			return nil
+		},
+	); err != nil {
+		return err
+	}
+	tabWriter := tabwriter.NewWriter(stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tabWriter, "LEVEL\tCOUNT")
+	for level := ledge.Level_NONE; level <= ledge.Level_PANIC; level++ {
+		if count, ok := levelToCount[level]; ok {
+			fmt.Fprintf(tabWriter, "%s\t%d\n", strings.ToLower(level.String()), count)
+		}
+	}
+	fmt.Fprintln(tabWriter)
+	fmt.Fprintln(tabWriter, "EVENT\tCOUNT")
+	var eventTypes []string
+	for eventType := range eventTypeToCount {
+		eventTypes = append(eventTypes, eventType)
+	}
+	sort.Strings(eventTypes)
+	for _, eventType := range eventTypes {
+		fmt.Fprintf(tabWriter, "%s\t%d\n", eventType, eventTypeToCount[eventType])
+	}
+	fmt.Fprintln(tabWriter)
+	fmt.Fprintf(tabWriter, "TOTAL\t%d\n", total)
+	return tabWriter.Flush()
+}
+
+func eventTypeName(event ledge.Event) string {
+	if rawEvent, ok := event.(*ledge.RawEvent); ok {
+		return ledge.ShortTypeName(rawEvent.TypeName)
+	}
+	return ledge.ShortTypeName(fmt.Sprintf("%T", event))
+}
+
+type stringSlice []string
+
+func (s *stringSlice) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSlice) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
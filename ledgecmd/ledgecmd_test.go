@@ -0,0 +1,109 @@
+package ledgecmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/codeship/go-ledge"
+)
+
+type testContext struct {
+	Foo string
+}
+
+type testEvent struct {
+	Bar string
+}
+
+type testUnregisteredEvent struct {
+	Baz string
+}
+
+func init() {
+	ledge.RegisterSpecification(
+		&ledge.Specification{
+			ContextTypes: []ledge.Context{testContext{}},
+			EventTypes:   []ledge.Event{&testEvent{}},
+		},
+	)
+}
+
+func TestRun(t *testing.T) {
+	input := bytes.NewBuffer(nil)
+	logger, err := ledge.NewLogger(
+		input,
+		ledge.ProtoMarshaller,
+		&ledge.Specification{
+			ContextTypes: []ledge.Context{testContext{}},
+			EventTypes:   []ledge.Event{&testEvent{}, &testUnregisteredEvent{}},
+		},
+		ledge.LoggerOptions{Encoder: ledge.RPCEncoder},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info(&testEvent{"one"})
+	logger.WithContext(testContext{"foo"}).Warn(&testEvent{"two"})
+	logger.Error(&testUnregisteredEvent{"three"})
+	data := input.Bytes()
+
+	stdout, exitCode := testRun(t, data, "cat", "-out", "json")
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %s", len(lines), stdout)
+	}
+	if !strings.Contains(lines[0], `"one"`) || !strings.Contains(lines[1], `"two"`) {
+		t.Errorf("unexpected output: %s", stdout)
+	}
+	if !strings.Contains(lines[2], `"event_type":"testUnregisteredEvent"`) || !strings.Contains(lines[2], `"level":"error"`) {
+		t.Errorf("expected raw event, got %s", lines[2])
+	}
+
+	stdout, exitCode = testRun(t, data, "filter", "-level", "warn", "-context", "testContext")
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	lines = strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], "two") {
+		t.Errorf("unexpected output: %s", stdout)
+	}
+
+	stdout, exitCode = testRun(t, data, "count")
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	for _, expected := range []string{"info   1", "warn   1", "error  1", "testEvent              2", "testUnregisteredEvent  1", "TOTAL  3"} {
+		if !strings.Contains(stdout, expected) {
+			t.Errorf("expected %q in output: %s", expected, stdout)
+		}
+	}
+
+	binaryData, exitCode := testRun(t, data, "cat", "-out", "proto-binary")
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", exitCode)
+	}
+	if stdout, exitCode = testRun(t, []byte(binaryData), "count"); exitCode != 0 || !strings.Contains(stdout, "TOTAL  3") {
+		t.Errorf("expected binary proto to be read back, got exit code %d: %s", exitCode, stdout)
+	}
+
+	if _, exitCode = testRun(t, data, "filter", "-expr", "level=="); exitCode != 1 {
+		t.Errorf("expected exit code 1 for invalid expression, got %d", exitCode)
+	}
+	if _, exitCode = testRun(t, data, "unknown"); exitCode != 2 {
+		t.Errorf("expected exit code 2 for unknown command, got %d", exitCode)
+	}
+}
+
+func testRun(t *testing.T, input []byte, args ...string) (string, int) {
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	exitCode := Run(args, bytes.NewReader(input), stdout, stderr)
+	if stderr.Len() > 0 {
+		t.Log(stderr.String())
+	}
+	return stdout.String(), exitCode
+}
@@ -0,0 +1,267 @@
+package ledgegrpc
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/codeship/go-ledge"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type serverInterceptor struct {
+	logger  ledge.Logger
+	options InterceptorOptions
+}
+
+func newServerInterceptor(
+	logger ledge.Logger,
+	options InterceptorOptions,
+) *serverInterceptor {
+	return &serverInterceptor{
+		logger,
+		getInterceptorOptions(options),
+	}
+}
+
+func (s *serverInterceptor) interceptUnary(
+	ctx context.Context,
+	request interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	start := now(s.options.Timer)
+	ctx = s.newContext(ctx)
+	response, err := handler(ctx, request)
+	s.logCompleted(ctx, info.FullMethod, false, start, err)
+	return response, err
+}
+
+func (s *serverInterceptor) interceptStream(
+	server interface{},
+	serverStream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	start := now(s.options.Timer)
+	ctx := s.newContext(serverStream.Context())
+	err := handler(server, &contextServerStream{serverStream, ctx})
+	s.logCompleted(ctx, info.FullMethod, true, start, err)
+	return err
+}
+
+func (s *serverInterceptor) newContext(ctx context.Context) context.Context {
+	var requestID RequestID
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(s.options.RequestIDKey); len(values) > 0 {
+			requestID = RequestID(values[0])
+		}
+	}
+	if requestID == "" {
+		requestID = RequestID(s.options.IDAllocator.Allocate())
+	}
+	return ledge.AppendContexts(ledge.NewContext(ctx, s.logger), requestID)
+}
+
+func (s *serverInterceptor) logCompleted(ctx context.Context, method string, stream bool, start time.Time, err error) {
+	var peerAddr string
+	if callPeer, ok := peer.FromContext(ctx); ok && callPeer.Addr != nil {
+		peerAddr = callPeer.Addr.String()
+	}
+	code := status.Code(err)
+	logCall(
+		ledge.FromContext(ctx),
+		code,
+		&ServerCallCompleted{
+			Method:   method,
+			Stream:   stream,
+			Code:     code.String(),
+			Error:    getErrorMessage(err),
+			Duration: now(s.options.Timer).Sub(start),
+			Peer:     peerAddr,
+		},
+	)
+}
+
+// contextServerStream returns the context.Context with the Logger and RequestID attached.
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (c *contextServerStream) Context() context.Context {
+	return c.ctx
+}
+
+type clientInterceptor struct {
+	logger  ledge.Logger
+	options InterceptorOptions
+}
+
+func newClientInterceptor(
+	logger ledge.Logger,
+	options InterceptorOptions,
+) *clientInterceptor {
+	return &clientInterceptor{
+		logger,
+		getInterceptorOptions(options),
+	}
+}
+
+func (c *clientInterceptor) interceptUnary(
+	ctx context.Context,
+	method string,
+	request interface{},
+	response interface{},
+	clientConn *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	callOptions ...grpc.CallOption,
+) error {
+	start := now(c.options.Timer)
+	ctx, logger := c.newContext(ctx)
+	callPeer := &peer.Peer{}
+	err := invoker(ctx, method, request, response, clientConn, append(callOptions, grpc.Peer(callPeer))...)
+	c.logCompleted(logger, method, false, start, callPeer, err)
+	return err
+}
+
+func (c *clientInterceptor) interceptStream(
+	ctx context.Context,
+	streamDesc *grpc.StreamDesc,
+	clientConn *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	callOptions ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	start := now(c.options.Timer)
+	ctx, logger := c.newContext(ctx)
+	callPeer := &peer.Peer{}
+	clientStream, err := streamer(ctx, streamDesc, clientConn, method, append(callOptions, grpc.Peer(callPeer))...)
+	if err != nil {
+		c.logCompleted(logger, method, true, start, callPeer, err)
+		return nil, err
+	}
+	return newLoggingClientStream(
+		clientStream,
+		streamDesc.ServerStreams,
+		func(err error) {
+			c.logCompleted(logger, method, true, start, callPeer, err)
+		},
+	), nil
+}
+
+// newContext returns ctx with the RequestID in the outgoing metadata, and the Logger with
+// the Contexts of ctx attached.
+func (c *clientInterceptor) newContext(ctx context.Context) (context.Context, ledge.Logger) {
+	requestID := getRequestID(ctx)
+	if requestID == "" {
+		requestID = RequestID(c.options.IDAllocator.Allocate())
+		ctx = ledge.AppendContexts(ctx, requestID)
+	}
+	ctx = metadata.AppendToOutgoingContext(ctx, c.options.RequestIDKey, string(requestID))
+	return ctx, ledge.WithContextsFromContext(c.logger, ctx)
+}
+
+func (c *clientInterceptor) logCompleted(logger ledge.Logger, method string, stream bool, start time.Time, callPeer *peer.Peer, err error) {
+	var peerAddr string
+	if callPeer.Addr != nil {
+		peerAddr = callPeer.Addr.String()
+	}
+	code := status.Code(err)
+	logCall(
+		logger,
+		code,
+		&ClientCallCompleted{
+			Method:   method,
+			Stream:   stream,
+			Code:     code.String(),
+			Error:    getErrorMessage(err),
+			Duration: now(c.options.Timer).Sub(start),
+			Peer:     peerAddr,
+		},
+	)
+}
+
+// loggingClientStream calls onCompleted once when receiving returns an error, or a response
+// for streams without server streaming.
+type loggingClientStream struct {
+	grpc.ClientStream
+	serverStreams bool
+	onCompleted   func(error)
+	once          sync.Once
+}
+
+func newLoggingClientStream(
+	clientStream grpc.ClientStream,
+	serverStreams bool,
+	onCompleted func(error),
+) *loggingClientStream {
+	return &loggingClientStream{
+		clientStream,
+		serverStreams,
+		onCompleted,
+		sync.Once{},
+	}
+}
+
+func (l *loggingClientStream) RecvMsg(m interface{}) error {
+	err := l.ClientStream.RecvMsg(m)
+	if err != nil || !l.serverStreams {
+		l.once.Do(func() {
+			if err == io.EOF {
+				l.onCompleted(nil)
+			} else {
+				l.onCompleted(err)
+			}
+		})
+	}
+	return err
+}
+
+func getRequestID(ctx context.Context) RequestID {
+	contexts := ledge.ContextsFromContext(ctx)
+	for i := len(contexts) - 1; i >= 0; i-- {
+		if requestID, ok := contexts[i].(RequestID); ok {
+			return requestID
+		}
+	}
+	return ""
+}
+
+func logCall(logger ledge.Logger, code codes.Code, event ledge.Event) {
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable, codes.DataLoss:
+		logger.Error(event)
+	default:
+		logger.Info(event)
+	}
+}
+
+func getErrorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return status.Convert(err).Message()
+}
+
+func getInterceptorOptions(options InterceptorOptions) InterceptorOptions {
+	if options.IDAllocator == nil {
+		options.IDAllocator = ledge.UUIDAllocator
+	}
+	if options.RequestIDKey == "" {
+		options.RequestIDKey = DefaultRequestIDKey
+	}
+	return options
+}
+
+func now(timer ledge.Timer) time.Time {
+	if timer != nil {
+		return timer.Now()
+	}
+	return time.Now().UTC()
+}
@@ -0,0 +1,137 @@
+/*
+Package ledgegrpc provides gRPC interceptors that log every call with ledge.
+
+The server interceptors take the RequestID of every call from the incoming metadata, or
+allocate one if there is none, attach it to the context.Context of the call with
+ledge.AppendContexts along with the Logger, and log a ServerCallCompleted Event when the
+handler returns. Handlers log with the RequestID attached by using the Logger returned by
+ledge.FromContext:
+
+	func (s *server) Foo(ctx context.Context, request *FooRequest) (*FooResponse, error) {
+		ledge.FromContext(ctx).Info(&FooEvent{})
+		...
+	}
+
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(ledgegrpc.NewUnaryServerInterceptor(logger, ledgegrpc.InterceptorOptions{})),
+		grpc.StreamInterceptor(ledgegrpc.NewStreamServerInterceptor(logger, ledgegrpc.InterceptorOptions{})),
+	)
+
+The client interceptors send the RequestID attached to the context.Context of the call in the
+outgoing metadata, allocating one if there is none, and log a ClientCallCompleted Event when
+the call completes. Calls made while handling a call therefore carry the same RequestID.
+
+The Logger must be able to marshal the types in Specification.
+*/
+package ledgegrpc
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/codeship/go-ledge"
+	"google.golang.org/grpc"
+)
+
+const (
+	// DefaultRequestIDKey is the metadata key the RequestID is sent in by default.
+	DefaultRequestIDKey = "x-request-id"
+)
+
+var (
+	// Specification is the ledge.Specification for the Context and Event types logged by this package.
+	// Merge it into the Specification of any Logger or Unmarshaller used with the interceptors.
+	Specification = &ledge.Specification{
+		ContextTypes: []ledge.Context{
+			RequestID(""),
+		},
+		EventTypes: []ledge.Event{
+			&ServerCallCompleted{},
+			&ClientCallCompleted{},
+		},
+	}
+)
+
+func init() {
+	ledge.RegisterAdapterPackage(reflect.TypeOf(RequestID("")).PkgPath())
+}
+
+// RequestID is the Context attached to every call handled or made by the interceptors.
+type RequestID string
+
+// ServerCallCompleted is the Event logged when the handler of a call returns, at the Error
+// Level for codes that indicate a server error and at the Info Level otherwise.
+type ServerCallCompleted struct {
+	// Method is the full method name, such as /package.Service/Method.
+	Method string
+	// Stream is true for streaming calls.
+	Stream bool
+	// Code is the name of the status code, such as OK or NotFound.
+	Code string
+	// Error is the message of the error returned by the handler, if any.
+	Error string
+	// Duration is the time from receiving the call until the handler returned.
+	Duration time.Duration
+	// Peer is the address of the client.
+	Peer string
+}
+
+// ClientCallCompleted is the Event logged when a call completes, at the Error Level for codes
+// that indicate a server error and at the Info Level otherwise.
+//
+// A streaming call completes when receiving from the stream returns an error, which is
+// io.EOF for successful calls, so calls are only logged for streams that are received
+// from until then.
+type ClientCallCompleted struct {
+	// Method is the full method name, such as /package.Service/Method.
+	Method string
+	// Stream is true for streaming calls.
+	Stream bool
+	// Code is the name of the status code, such as OK or NotFound.
+	Code string
+	// Error is the message of the error the call failed with, if any.
+	Error string
+	// Duration is the time from starting the call until it completed.
+	Duration time.Duration
+	// Peer is the address of the server, if a connection was made.
+	Peer string
+}
+
+// InterceptorOptions are the options for the interceptors.
+type InterceptorOptions struct {
+	// IDAllocator specifies the IDAllocator used to allocate a RequestID for calls without one.
+	// If not specified, ledge.UUIDAllocator will be used.
+	IDAllocator ledge.IDAllocator
+	// Timer specifies an alternate Timer to measure the Duration of calls with.
+	// If not specified, the system time will be used.
+	Timer ledge.Timer
+	// RequestIDKey specifies the metadata key the RequestID is sent in.
+	// If not specified, DefaultRequestIDKey will be used.
+	RequestIDKey string
+}
+
+// NewUnaryServerInterceptor returns a new grpc.UnaryServerInterceptor that logs every unary
+// call to the given Logger.
+func NewUnaryServerInterceptor(logger ledge.Logger, options InterceptorOptions) grpc.UnaryServerInterceptor {
+	return newServerInterceptor(logger, options).interceptUnary
+}
+
+// NewStreamServerInterceptor returns a new grpc.StreamServerInterceptor that logs every
+// streaming call to the given Logger.
+func NewStreamServerInterceptor(logger ledge.Logger, options InterceptorOptions) grpc.StreamServerInterceptor {
+	return newServerInterceptor(logger, options).interceptStream
+}
+
+// NewUnaryClientInterceptor returns a new grpc.UnaryClientInterceptor that logs every unary
+// call to the given Logger, with the Contexts attached to the context.Context of the call
+// with ledge.AppendContexts.
+func NewUnaryClientInterceptor(logger ledge.Logger, options InterceptorOptions) grpc.UnaryClientInterceptor {
+	return newClientInterceptor(logger, options).interceptUnary
+}
+
+// NewStreamClientInterceptor returns a new grpc.StreamClientInterceptor that logs every
+// streaming call to the given Logger, with the Contexts attached to the context.Context of
+// the call with ledge.AppendContexts.
+func NewStreamClientInterceptor(logger ledge.Logger, options InterceptorOptions) grpc.StreamClientInterceptor {
+	return newClientInterceptor(logger, options).interceptStream
+}
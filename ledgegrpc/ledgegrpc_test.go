@@ -0,0 +1,228 @@
+package ledgegrpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/codeship/go-ledge"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type testEvent struct {
+	Method string
+}
+
+type testIDAllocator struct {
+	count int
+}
+
+func (t *testIDAllocator) Allocate() string {
+	t.count++
+	return fmt.Sprintf("request-%d", t.count)
+}
+
+type testTimer struct{}
+
+func (t testTimer) Now() time.Time {
+	return time.Unix(0, 0).UTC()
+}
+
+func TestInterceptors(t *testing.T) {
+	specification := ledge.MergeSpecifications(Specification, &ledge.Specification{EventTypes: []ledge.Event{&testEvent{}}})
+	// a FakeLogger stops reading at the first EOF, so Entry objects logged by the server
+	// goroutines are written to buffers and read once the server is stopped
+	serverBuffer := bytes.NewBuffer(nil)
+	serverLogger, err := ledge.NewLogger(serverBuffer, ledge.ProtoMarshaller, specification, ledge.LoggerOptions{Encoder: ledge.RPCEncoder})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientBuffer := bytes.NewBuffer(nil)
+	clientLogger, err := ledge.NewLogger(clientBuffer, ledge.ProtoMarshaller, specification, ledge.LoggerOptions{Encoder: ledge.RPCEncoder})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			NewUnaryServerInterceptor(serverLogger, InterceptorOptions{Timer: testTimer{}}),
+			func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+				ledge.FromContext(ctx).Info(&testEvent{info.FullMethod})
+				return handler(ctx, request)
+			},
+		),
+		grpc.StreamInterceptor(NewStreamServerInterceptor(serverLogger, InterceptorOptions{Timer: testTimer{}})),
+	)
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	clientConn, err := grpc.NewClient(
+		"passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(NewUnaryClientInterceptor(clientLogger, InterceptorOptions{IDAllocator: &testIDAllocator{}, Timer: testTimer{}})),
+		grpc.WithStreamInterceptor(NewStreamClientInterceptor(clientLogger, InterceptorOptions{Timer: testTimer{}})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	healthClient := grpc_health_v1.NewHealthClient(clientConn)
+
+	if _, err := healthClient.Check(
+		ledge.AppendContexts(context.Background(), RequestID("abc")),
+		&grpc_health_v1.HealthCheckRequest{},
+	); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := healthClient.Check(
+		context.Background(),
+		&grpc_health_v1.HealthCheckRequest{Service: "unknown"},
+	); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(ledge.AppendContexts(context.Background(), RequestID("def")))
+	watchClient, err := healthClient.Watch(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := watchClient.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, err := watchClient.Recv(); status.Code(err) != codes.Canceled {
+		t.Fatalf("expected Canceled, got %v", err)
+	}
+	if err := clientConn.Close(); err != nil {
+		t.Fatal(err)
+	}
+	server.GracefulStop()
+
+	checkEntries(
+		t,
+		specification,
+		serverBuffer,
+		[]*ledge.Entry{
+			&ledge.Entry{
+				Level:    ledge.Level_INFO,
+				Contexts: []ledge.Context{RequestID("abc")},
+				Event:    &testEvent{"/grpc.health.v1.Health/Check"},
+			},
+			&ledge.Entry{
+				Level:    ledge.Level_INFO,
+				Contexts: []ledge.Context{RequestID("abc")},
+				Event:    &ServerCallCompleted{"/grpc.health.v1.Health/Check", false, "OK", "", 0, "bufconn"},
+			},
+			&ledge.Entry{
+				Level:    ledge.Level_INFO,
+				Contexts: []ledge.Context{RequestID("request-1")},
+				Event:    &testEvent{"/grpc.health.v1.Health/Check"},
+			},
+			&ledge.Entry{
+				Level:    ledge.Level_INFO,
+				Contexts: []ledge.Context{RequestID("request-1")},
+				Event:    &ServerCallCompleted{"/grpc.health.v1.Health/Check", false, "NotFound", "unknown service", 0, "bufconn"},
+			},
+			&ledge.Entry{
+				Level:    ledge.Level_INFO,
+				Contexts: []ledge.Context{RequestID("def")},
+				Event:    &ServerCallCompleted{"/grpc.health.v1.Health/Watch", true, "Canceled", "Stream has ended.", 0, "bufconn"},
+			},
+		},
+	)
+	checkEntries(
+		t,
+		specification,
+		clientBuffer,
+		[]*ledge.Entry{
+			&ledge.Entry{
+				Level:    ledge.Level_INFO,
+				Contexts: []ledge.Context{RequestID("abc")},
+				Event:    &ClientCallCompleted{"/grpc.health.v1.Health/Check", false, "OK", "", 0, "bufconn"},
+			},
+			&ledge.Entry{
+				Level:    ledge.Level_INFO,
+				Contexts: []ledge.Context{RequestID("request-1")},
+				Event:    &ClientCallCompleted{"/grpc.health.v1.Health/Check", false, "NotFound", "unknown service", 0, "bufconn"},
+			},
+			&ledge.Entry{
+				Level:    ledge.Level_INFO,
+				Contexts: []ledge.Context{RequestID("def")},
+				Event:    &ClientCallCompleted{"/grpc.health.v1.Health/Watch", true, "Canceled", "context canceled", 0, "bufconn"},
+			},
+		},
+	)
+}
+
+func TestLogCallLevel(t *testing.T) {
+	fakeLogger, err := ledge.NewFakeLogger(Specification)
+	if err != nil {
+		t.Fatal(err)
+	}
+	interceptor := NewUnaryServerInterceptor(fakeLogger, InterceptorOptions{})
+	for _, code := range []codes.Code{codes.OK, codes.InvalidArgument, codes.Internal, codes.Unavailable} {
+		_, _ = interceptor(
+			context.Background(),
+			nil,
+			&grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"},
+			func(ctx context.Context, request interface{}) (interface{}, error) {
+				if code == codes.OK {
+					return nil, nil
+				}
+				return nil, status.Error(code, "failed")
+			},
+		)
+	}
+	entries, err := fakeLogger.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedLevels := []ledge.Level{ledge.Level_INFO, ledge.Level_INFO, ledge.Level_ERROR, ledge.Level_ERROR}
+	if len(entries) != len(expectedLevels) {
+		t.Fatalf("expected %d entries, got %d", len(expectedLevels), len(entries))
+	}
+	for i, entry := range entries {
+		if entry.Level != expectedLevels[i] {
+			t.Errorf("expected level %v, got %v", expectedLevels[i], entry.Level)
+		}
+		if requestID, ok := entry.Contexts[0].(RequestID); !ok || requestID == "" {
+			t.Errorf("expected allocated RequestID, got %v", entry.Contexts)
+		}
+	}
+}
+
+func checkEntries(t *testing.T, specification *ledge.Specification, buffer *bytes.Buffer, expected []*ledge.Entry) {
+	unmarshaller, err := ledge.NewProtoUnmarshaller(specification)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entryReader, err := ledge.NewEntryReader(buffer, unmarshaller, ledge.RPCDecoder, ledge.EntryReaderOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ledge.NewBlockingEntryReader(entryReader).Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d", len(expected), len(entries))
+	}
+	for i, entry := range entries {
+		if entry.Level != expected[i].Level || !reflect.DeepEqual(entry.Contexts, expected[i].Contexts) || !reflect.DeepEqual(entry.Event, expected[i].Event) {
+			t.Errorf("expected %v %v %+v, got %v %v %+v", expected[i].Level, expected[i].Contexts, expected[i].Event, entry.Level, entry.Contexts, entry.Event)
+		}
+	}
+}
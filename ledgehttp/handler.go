@@ -0,0 +1,193 @@
+package ledgehttp
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/codeship/go-ledge"
+)
+
+type handler struct {
+	handler http.Handler
+	logger  ledge.Logger
+	options HandlerOptions
+}
+
+func newHandler(
+	delegate http.Handler,
+	logger ledge.Logger,
+	options HandlerOptions,
+) *handler {
+	if options.IDAllocator == nil {
+		options.IDAllocator = ledge.UUIDAllocator
+	}
+	return &handler{
+		delegate,
+		logger,
+		options,
+	}
+}
+
+func (h *handler) ServeHTTP(responseWriter http.ResponseWriter, request *http.Request) {
+	start := h.now()
+	var requestID RequestID
+	if h.options.RequestIDHeader != "" {
+		requestID = RequestID(request.Header.Get(h.options.RequestIDHeader))
+	}
+	if requestID == "" {
+		requestID = RequestID(h.options.IDAllocator.Allocate())
+	}
+	if h.options.RequestIDHeader != "" {
+		responseWriter.Header().Set(h.options.RequestIDHeader, string(requestID))
+	}
+	ctx := ledge.AppendContexts(ledge.NewContext(request.Context(), h.logger), requestID)
+	request = request.WithContext(ctx)
+	logger := ledge.FromContext(ctx)
+	if !h.options.NoRequestStarted {
+		logger.Info(
+			&RequestStarted{
+				Method:        request.Method,
+				URL:           request.URL.String(),
+				Proto:         request.Proto,
+				RemoteAddr:    request.RemoteAddr,
+				UserAgent:     request.UserAgent(),
+				ContentLength: request.ContentLength,
+			},
+		)
+	}
+	statusResponseWriter := newStatusResponseWriter(responseWriter)
+	h.handler.ServeHTTP(statusResponseWriter, request)
+	requestCompleted := &RequestCompleted{
+		Method:   request.Method,
+		URL:      request.URL.String(),
+		Status:   statusResponseWriter.getStatus(),
+		Bytes:    statusResponseWriter.bytes,
+		Duration: h.now().Sub(start),
+	}
+	if requestCompleted.Status >= http.StatusInternalServerError {
+		logger.Error(requestCompleted)
+	} else {
+		logger.Info(requestCompleted)
+	}
+}
+
+func (h *handler) now() time.Time {
+	if h.options.Timer != nil {
+		return h.options.Timer.Now()
+	}
+	return time.Now().UTC()
+}
+
+type recoveryHandler struct {
+	handler http.Handler
+}
+
+func newRecoveryHandler(
+	delegate http.Handler,
+) *recoveryHandler {
+	return &recoveryHandler{
+		delegate,
+	}
+}
+
+func (r *recoveryHandler) ServeHTTP(responseWriter http.ResponseWriter, request *http.Request) {
+	statusResponseWriter := newStatusResponseWriter(responseWriter)
+	defer func() {
+		value := recover()
+		if value == nil {
+			return
+		}
+		if value == http.ErrAbortHandler {
+			panic(value)
+		}
+		logPanic(
+			ledge.FromContext(request.Context()),
+			&RequestPanic{
+				Method: request.Method,
+				URL:    request.URL.String(),
+				Value:  fmt.Sprint(value),
+				Stack:  string(debug.Stack()),
+			},
+		)
+		if statusResponseWriter.status == 0 {
+			http.Error(statusResponseWriter, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+	}()
+	r.handler.ServeHTTP(statusResponseWriter, request)
+}
+
+// logPanic recovers the panic of Logger.Panic, which happens after the Entry is written.
+func logPanic(logger ledge.Logger, requestPanic *RequestPanic) {
+	if logger == nil {
+		return
+	}
+	defer func() {
+		_ = recover()
+	}()
+	logger.Panic(requestPanic)
+}
+
+type statusResponseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func newStatusResponseWriter(
+	responseWriter http.ResponseWriter,
+) *statusResponseWriter {
+	return &statusResponseWriter{
+		responseWriter,
+		0,
+		0,
+	}
+}
+
+func (s *statusResponseWriter) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusResponseWriter) Write(p []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	n, err := s.ResponseWriter.Write(p)
+	s.bytes += int64(n)
+	return n, err
+}
+
+func (s *statusResponseWriter) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		if s.status == 0 {
+			s.status = http.StatusOK
+		}
+		flusher.Flush()
+	}
+}
+
+func (s *statusResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := s.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("ledge: %T is not an http.Hijacker", s.ResponseWriter)
+	}
+	return hijacker.Hijack()
+}
+
+// Unwrap allows http.ResponseController to reach the wrapped http.ResponseWriter.
+func (s *statusResponseWriter) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+func (s *statusResponseWriter) getStatus() int {
+	if s.status == 0 {
+		return http.StatusOK
+	}
+	return s.status
+}
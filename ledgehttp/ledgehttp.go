@@ -0,0 +1,115 @@
+/*
+Package ledgehttp provides net/http middleware that logs every request with ledge.
+
+NewHandler allocates a RequestID for every request, attaches it to the context.Context of
+the request with ledge.AppendContexts along with the Logger, and logs a RequestStarted Event
+when the request is received and a RequestCompleted Event when the wrapped http.Handler
+returns. Code handling the request logs with the RequestID attached by using the Logger
+returned by ledge.FromContext:
+
+	func handle(responseWriter http.ResponseWriter, request *http.Request) {
+		ledge.FromContext(request.Context()).Info(&FooEvent{})
+	}
+
+	http.ListenAndServe(":8080", ledgehttp.NewHandler(ledgehttp.NewRecoveryHandler(http.HandlerFunc(handle)), logger, ledgehttp.HandlerOptions{}))
+
+The Logger must be able to marshal the types in Specification.
+*/
+package ledgehttp
+
+import (
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/codeship/go-ledge"
+)
+
+var (
+	// Specification is the ledge.Specification for the Context and Event types logged by this package.
+	// Merge it into the Specification of any Logger or Unmarshaller used with a Handler.
+	Specification = &ledge.Specification{
+		ContextTypes: []ledge.Context{
+			RequestID(""),
+		},
+		EventTypes: []ledge.Event{
+			&RequestStarted{},
+			&RequestCompleted{},
+			&RequestPanic{},
+		},
+	}
+)
+
+func init() {
+	ledge.RegisterAdapterPackage(reflect.TypeOf(RequestID("")).PkgPath())
+}
+
+// RequestID is the Context attached to every request handled by a Handler.
+type RequestID string
+
+// RequestStarted is the Event logged at the Info Level when a request is received.
+type RequestStarted struct {
+	Method        string
+	URL           string
+	Proto         string
+	RemoteAddr    string
+	UserAgent     string
+	ContentLength int64
+}
+
+// RequestCompleted is the Event logged when the wrapped http.Handler returns, at the Error
+// Level for 5xx statuses and at the Info Level otherwise.
+type RequestCompleted struct {
+	Method string
+	URL    string
+	// Status is the status code written, http.StatusOK if the handler wrote none.
+	Status int
+	// Bytes is the number of bytes of the response body written.
+	Bytes int64
+	// Duration is the time from receiving the request until the handler returned.
+	Duration time.Duration
+}
+
+// RequestPanic is the Event logged at the Panic Level when the http.Handler wrapped by a
+// recovery handler panics.
+type RequestPanic struct {
+	Method string
+	URL    string
+	// Value is the value passed to panic, as formatted by fmt.Sprint.
+	Value string
+	// Stack is the stack trace of the panicking goroutine.
+	Stack string
+}
+
+// HandlerOptions are the options for a Handler.
+type HandlerOptions struct {
+	// IDAllocator specifies the IDAllocator used to allocate a RequestID for every request.
+	// If not specified, ledge.UUIDAllocator will be used.
+	IDAllocator ledge.IDAllocator
+	// Timer specifies an alternate Timer to measure the Duration of requests with.
+	// If not specified, the system time will be used.
+	Timer ledge.Timer
+	// RequestIDHeader specifies a header, such as X-Request-ID, that an incoming RequestID is
+	// taken from instead of allocating one. The RequestID is set on the response in this header.
+	RequestIDHeader string
+	// NoRequestStarted specifies that no RequestStarted Event is logged.
+	NoRequestStarted bool
+}
+
+// NewHandler returns a new http.Handler that logs every request handled by the given
+// http.Handler to the given Logger.
+func NewHandler(handler http.Handler, logger ledge.Logger, options HandlerOptions) http.Handler {
+	return newHandler(handler, logger, options)
+}
+
+// NewRecoveryHandler returns a new http.Handler that recovers panics in the given
+// http.Handler, logs them as a RequestPanic at the Panic Level, and responds with
+// http.StatusInternalServerError if nothing was written yet. Panicking when logging at
+// the Panic Level is recovered as well, so the server keeps running. Panics with
+// http.ErrAbortHandler are not logged and are panicked again, as net/http expects.
+//
+// The Logger returned by ledge.FromContext for the request is used, so the recovery
+// handler is usually wrapped by a Handler.
+func NewRecoveryHandler(handler http.Handler) http.Handler {
+	return newRecoveryHandler(handler)
+}
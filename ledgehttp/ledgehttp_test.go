@@ -0,0 +1,126 @@
+package ledgehttp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codeship/go-ledge"
+)
+
+type testEvent struct {
+	Foo string
+}
+
+type testIDAllocator struct {
+	count int
+}
+
+func (t *testIDAllocator) Allocate() string {
+	t.count++
+	return fmt.Sprintf("request-%d", t.count)
+}
+
+type testTimer struct {
+	now time.Time
+}
+
+func (t *testTimer) Now() time.Time {
+	return t.now
+}
+
+func TestHandler(t *testing.T) {
+	fakeLogger, err := ledge.NewFakeLogger(ledge.MergeSpecifications(Specification, &ledge.Specification{EventTypes: []ledge.Event{&testEvent{}}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	timer := &testTimer{time.Unix(0, 0).UTC()}
+	handler := NewHandler(
+		NewRecoveryHandler(
+			http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+				if request.URL.Path == "/panic" {
+					panic("boom")
+				}
+				ledge.FromContext(request.Context()).Info(&testEvent{"handled"})
+				timer.now = timer.now.Add(2 * time.Second)
+				responseWriter.WriteHeader(http.StatusCreated)
+				_, _ = responseWriter.Write([]byte("hello"))
+			}),
+		),
+		fakeLogger,
+		HandlerOptions{
+			IDAllocator:     &testIDAllocator{},
+			Timer:           timer,
+			RequestIDHeader: "X-Request-ID",
+		},
+	)
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/foo?bar=baz", strings.NewReader("body"))
+	request.Header.Set("User-Agent", "test")
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusCreated || recorder.Body.String() != "hello" || recorder.Header().Get("X-Request-ID") != "request-1" {
+		t.Errorf("unexpected response %d %s %v", recorder.Code, recorder.Body.String(), recorder.Header())
+	}
+
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodGet, "/panic", nil)
+	request.Header.Set("X-Request-ID", "incoming")
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, recorder.Code)
+	}
+
+	entries, err := fakeLogger.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 6 {
+		t.Fatalf("expected 6 entries, got %d", len(entries))
+	}
+	requestPanic, ok := entries[4].Event.(*RequestPanic)
+	if !ok || !strings.Contains(requestPanic.Stack, "ledgehttp.TestHandler") {
+		t.Errorf("expected stack in RequestPanic, got %v", entries[4].Event)
+	}
+	requestPanic.Stack = ""
+	for i, expected := range []*ledge.Entry{
+		&ledge.Entry{
+			Level:    ledge.Level_INFO,
+			Contexts: []ledge.Context{RequestID("request-1")},
+			Event:    &RequestStarted{"POST", "/foo?bar=baz", "HTTP/1.1", "192.0.2.1:1234", "test", 4},
+		},
+		&ledge.Entry{
+			Level:    ledge.Level_INFO,
+			Contexts: []ledge.Context{RequestID("request-1")},
+			Event:    &testEvent{"handled"},
+		},
+		&ledge.Entry{
+			Level:    ledge.Level_INFO,
+			Contexts: []ledge.Context{RequestID("request-1")},
+			Event:    &RequestCompleted{"POST", "/foo?bar=baz", http.StatusCreated, 5, 2 * time.Second},
+		},
+		&ledge.Entry{
+			Level:    ledge.Level_INFO,
+			Contexts: []ledge.Context{RequestID("incoming")},
+			Event:    &RequestStarted{"GET", "/panic", "HTTP/1.1", "192.0.2.1:1234", "", 0},
+		},
+		&ledge.Entry{
+			Level:    ledge.Level_PANIC,
+			Contexts: []ledge.Context{RequestID("incoming")},
+			Event:    &RequestPanic{"GET", "/panic", "boom", ""},
+		},
+		&ledge.Entry{
+			Level:    ledge.Level_ERROR,
+			Contexts: []ledge.Context{RequestID("incoming")},
+			Event:    &RequestCompleted{"GET", "/panic", http.StatusInternalServerError, 22, 0},
+		},
+	} {
+		if entries[i].Level != expected.Level || !reflect.DeepEqual(entries[i].Contexts, expected.Contexts) || !reflect.DeepEqual(entries[i].Event, expected.Event) {
+			t.Errorf("expected %v %v %+v, got %v %v %+v", expected.Level, expected.Contexts, expected.Event, entries[i].Level, entries[i].Contexts, entries[i].Event)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package ledgeslog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+
+	"github.com/codeship/go-ledge"
+)
+
+// handler keeps the attributes added with WithAttrs in a single Attrs context, as
+// JSONMarshaller keys contexts by type and would only keep the last of several.
+type handler struct {
+	logger      ledge.Logger
+	options     HandlerOptions
+	groups      []string
+	attrs       []Attr
+	attrsLogger ledge.Logger
+}
+
+func newHandler(
+	logger ledge.Logger,
+	options HandlerOptions,
+	groups []string,
+	attrs []Attr,
+) *handler {
+	attrsLogger := logger
+	if len(attrs) > 0 {
+		attrsLogger = logger.WithContext(&Attrs{attrs})
+	}
+	return &handler{
+		logger,
+		options,
+		groups,
+		attrs,
+		attrsLogger,
+	}
+}
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.options.Level != nil {
+		minLevel = h.options.Level.Level()
+	}
+	return level >= minLevel
+}
+
+func (h *handler) Handle(ctx context.Context, record slog.Record) error {
+	var attrs []Attr
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = appendAttr(attrs, attr)
+		return true
+	})
+	event := &Event{
+		Msg:   record.Message,
+		Time:  record.Time,
+		Level: int64(record.Level),
+		Attrs: nestAttrs(h.groups, attrs),
+	}
+	if h.options.AddSource && record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		event.Source = &ledge.Caller{
+			File:     frame.File,
+			Line:     frame.Line,
+			Function: frame.Function,
+		}
+	}
+	logger := ledge.WithContextsFromContext(h.attrsLogger, ctx)
+	switch {
+	case record.Level < slog.LevelInfo:
+		logger.Debug(event)
+	case record.Level < slog.LevelWarn:
+		logger.Info(event)
+	case record.Level < slog.LevelError:
+		logger.Warn(event)
+	default:
+		logger.Error(event)
+	}
+	return nil
+}
+
+func (h *handler) WithAttrs(slogAttrs []slog.Attr) slog.Handler {
+	var attrs []Attr
+	for _, slogAttr := range slogAttrs {
+		attrs = appendAttr(attrs, slogAttr)
+	}
+	if len(attrs) == 0 {
+		return h
+	}
+	return newHandler(h.logger, h.options, h.groups, mergeAttrs(append([]Attr(nil), h.attrs...), nestAttrs(h.groups, attrs)))
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := make([]string, len(h.groups), len(h.groups)+1)
+	copy(groups, h.groups)
+	return &handler{h.logger, h.options, append(groups, name), h.attrs, h.attrsLogger}
+}
+
+// appendAttr follows the slog.Handler rules: empty attributes and groups are dropped,
+// and groups with an empty key are inlined.
+func appendAttr(attrs []Attr, slogAttr slog.Attr) []Attr {
+	slogAttr.Value = slogAttr.Value.Resolve()
+	if slogAttr.Equal(slog.Attr{}) {
+		return attrs
+	}
+	if slogAttr.Value.Kind() != slog.KindGroup {
+		return append(attrs, Attr{slogAttr.Key, getAttrValue(slogAttr.Value)})
+	}
+	var groupAttrs []Attr
+	for _, groupSlogAttr := range slogAttr.Value.Group() {
+		groupAttrs = appendAttr(groupAttrs, groupSlogAttr)
+	}
+	if len(groupAttrs) == 0 {
+		return attrs
+	}
+	if slogAttr.Key == "" {
+		return append(attrs, groupAttrs...)
+	}
+	return append(attrs, Attr{slogAttr.Key, groupAttrs})
+}
+
+func getAttrValue(value slog.Value) interface{} {
+	switch value.Kind() {
+	case slog.KindString:
+		return value.String()
+	case slog.KindInt64:
+		return value.Int64()
+	case slog.KindUint64:
+		return value.Uint64()
+	case slog.KindFloat64:
+		return value.Float64()
+	case slog.KindBool:
+		return value.Bool()
+	case slog.KindDuration:
+		return value.Duration()
+	case slog.KindTime:
+		return value.Time()
+	default:
+		if err, ok := value.Any().(error); ok {
+			return err.Error()
+		}
+		return fmt.Sprint(value.Any())
+	}
+}
+
+// nestAttrs nests attrs in the given groups, innermost last. Groups with no
+// attributes are dropped, so this returns nil if attrs is empty.
+func nestAttrs(groups []string, attrs []Attr) []Attr {
+	if len(attrs) == 0 {
+		return nil
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		attrs = []Attr{{groups[i], attrs}}
+	}
+	return attrs
+}
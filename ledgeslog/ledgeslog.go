@@ -0,0 +1,126 @@
+/*
+Package ledgeslog bridges Go's log/slog package and ledge.
+
+NewHandler returns a slog.Handler that logs every slog.Record to a ledge.Logger as an Event,
+so that code written against log/slog can be logged with ledge:
+
+	logger, err := ledge.NewLogger(os.Stderr, ledge.ProtoMarshaller, ledgeslog.Specification, ledge.LoggerOptions{})
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(slog.New(ledgeslog.NewHandler(logger, ledgeslog.HandlerOptions{})))
+
+NewLogger goes the other way, and returns a ledge.Logger that forwards every Entry to an
+existing slog.Handler, so that code written against ledge can be logged with log/slog:
+
+	logger, err := ledgeslog.NewLogger(slog.NewJSONHandler(os.Stderr, nil), specification, ledge.LoggerOptions{})
+*/
+package ledgeslog
+
+import (
+	"encoding/gob"
+	"io"
+	"log/slog"
+	"reflect"
+	"time"
+
+	"github.com/codeship/go-ledge"
+)
+
+var (
+	// Specification is the ledge.Specification for the Context and Event types logged by a Handler.
+	// Merge it into the Specification of any Logger or Unmarshaller used with a Handler.
+	Specification = &ledge.Specification{
+		ContextTypes: []ledge.Context{
+			&Attrs{},
+		},
+		EventTypes: []ledge.Event{
+			&Event{},
+		},
+	}
+)
+
+func init() {
+	ledge.RegisterAdapterPackage(reflect.TypeOf(Attr{}).PkgPath())
+	gob.Register(time.Time{})
+	gob.Register(time.Duration(0))
+	gob.Register([]Attr(nil))
+}
+
+// Attr is a resolved slog.Attr.
+//
+// Value is a string, int64, uint64, float64, bool, time.Duration or time.Time for the
+// corresponding slog.Kind, and a []Attr for slog.KindGroup. Values of slog.KindAny
+// are stored as the result of fmt.Sprint, or of the Error method for errors.
+type Attr struct {
+	Key   string
+	Value interface{}
+}
+
+// Attrs is the Context holding every attribute added with Handler.WithAttrs.
+//
+// Attrs added within groups opened by Handler.WithGroup are nested in group Attr values.
+type Attrs struct {
+	Attrs []Attr
+}
+
+// Event is the Event logged by a Handler for every slog.Record.
+//
+// Attrs of the slog.Record added within groups opened by Handler.WithGroup are nested
+// in group Attr values.
+type Event struct {
+	// Msg is the message of the slog.Record.
+	Msg string
+	// Time is the time of the slog.Record, which is zero if the slog.Record has no time.
+	Time time.Time
+	// Level is the slog.Level of the slog.Record, which is more fine-grained than the ledge.Level.
+	Level int64
+	// Source is the source location of the slog.Record, if HandlerOptions.AddSource was set.
+	Source *ledge.Caller
+	// Attrs is the attributes of the slog.Record.
+	Attrs []Attr
+}
+
+// HandlerOptions are the options for a Handler.
+type HandlerOptions struct {
+	// Level is the minimum slog.Level that is enabled, slog.LevelInfo if not set.
+	// The Filters of the ledge.Logger are applied after this.
+	Level slog.Leveler
+	// AddSource records the source location of every slog.Record in Event.Source.
+	AddSource bool
+}
+
+// NewHandler returns a new slog.Handler that logs to the given ledge.Logger.
+//
+// The slog.Level of every slog.Record is mapped to ledge.Level_DEBUG, ledge.Level_INFO,
+// ledge.Level_WARN or ledge.Level_ERROR, rounding down. The Logger must be able to
+// marshal the types in Specification. Contexts attached to the context.Context passed
+// to the slog.Handler with ledge.AppendContexts are attached to every Entry.
+func NewHandler(logger ledge.Logger, options HandlerOptions) slog.Handler {
+	return newHandler(logger, options, nil, nil)
+}
+
+// NewLogger returns a new ledge.Logger that forwards every Entry to the given slog.Handler.
+//
+// Every Entry is converted with NewRecord. The Marshaller, Encoder and io.Writer of the
+// Logger are not used.
+func NewLogger(handler slog.Handler, specification *ledge.Specification, options ledge.LoggerOptions) (ledge.Logger, error) {
+	options.Encoder = nil
+	return ledge.NewLogger(
+		io.Discard,
+		newHandlerMarshaller(handler),
+		ledge.MergeSpecifications(specification, Specification),
+		options,
+	)
+}
+
+// NewRecord returns a new slog.Record for the given Entry.
+//
+// Entry objects logged by a Handler are converted back into the slog.Record that was
+// handled, with the Attrs contexts merged in. Other Entry objects are converted with their
+// ledge.Level, with the Fields of a ledge.UnstructuredEvent as attributes, with any other Event
+// and every Context as slog.KindAny attributes keyed by the name of their type, and with the
+// ID of the Entry as the "id" attribute.
+func NewRecord(entry *ledge.Entry) slog.Record {
+	return newRecord(entry)
+}
@@ -0,0 +1,243 @@
+package ledgeslog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"testing"
+	"testing/slogtest"
+
+	"github.com/codeship/go-ledge"
+)
+
+func TestHandler(t *testing.T) {
+	for _, marshaller := range []struct {
+		name         string
+		marshaller   ledge.Marshaller
+		encoder      ledge.Encoder
+		unmarshaller func() (ledge.Unmarshaller, error)
+		decoder      ledge.Decoder
+	}{
+		{
+			"proto",
+			ledge.ProtoMarshaller,
+			ledge.RPCEncoder,
+			func() (ledge.Unmarshaller, error) { return ledge.NewProtoUnmarshaller(Specification) },
+			ledge.RPCDecoder,
+		},
+		{
+			"json",
+			ledge.JSONMarshaller,
+			nil,
+			func() (ledge.Unmarshaller, error) {
+				return ledge.NewJSONUnmarshaller(Specification, ledge.JSONUnmarshallerOptions{})
+			},
+			nil,
+		},
+	} {
+		t.Run(marshaller.name, func(t *testing.T) {
+			buffer := bytes.NewBuffer(nil)
+			logger, err := ledge.NewLogger(buffer, marshaller.marshaller, Specification, ledge.LoggerOptions{Encoder: marshaller.encoder})
+			if err != nil {
+				t.Fatal(err)
+			}
+			slogtest.Run(
+				t,
+				func(t *testing.T) slog.Handler {
+					buffer.Reset()
+					return NewHandler(logger, HandlerOptions{})
+				},
+				func(t *testing.T) map[string]interface{} {
+					unmarshaller, err := marshaller.unmarshaller()
+					if err != nil {
+						t.Fatal(err)
+					}
+					entries := readEntries(t, buffer, unmarshaller, marshaller.decoder)
+					if len(entries) != 1 {
+						t.Fatalf("expected 1 entry, got %d", len(entries))
+					}
+					return recordToMap(NewRecord(entries[0]))
+				},
+			)
+		})
+	}
+}
+
+func TestLogger(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	jsonHandler := slog.NewJSONHandler(buffer, &slog.HandlerOptions{Level: slog.LevelDebug})
+	logger, err := NewLogger(jsonHandler, &ledge.Specification{}, ledge.LoggerOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	slogtest.Run(
+		t,
+		func(t *testing.T) slog.Handler {
+			buffer.Reset()
+			return NewHandler(logger, HandlerOptions{})
+		},
+		func(t *testing.T) map[string]interface{} {
+			var m map[string]interface{}
+			if err := json.Unmarshal(buffer.Bytes(), &m); err != nil {
+				t.Fatal(err)
+			}
+			return m
+		},
+	)
+
+	buffer.Reset()
+	ctx := ledge.AppendContexts(context.Background(), &Attrs{[]Attr{{"request_id", "abc"}}})
+	slog.New(NewHandler(logger, HandlerOptions{})).InfoContext(ctx, "context")
+	var m map[string]interface{}
+	if err := json.Unmarshal(buffer.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["request_id"] != "abc" {
+		t.Errorf("expected request_id from context: %s", buffer.String())
+	}
+
+	buffer.Reset()
+	logger.Unstructured().WithField("n", 1).Warn("hello")
+	m = nil
+	if err := json.Unmarshal(buffer.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m[slog.MessageKey] != "hello" || m[slog.LevelKey] != "WARN" || m[idKey] == "" || m["n"] != 1.0 {
+		t.Errorf("unexpected output: %s", buffer.String())
+	}
+
+	buffer.Reset()
+	logger.Info(&ledge.ErrorEvent{Msg: "error"})
+	m = nil
+	if err := json.Unmarshal(buffer.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m["ErrorEvent"].(map[string]interface{}); !ok || m[slog.MessageKey] != "error" {
+		t.Errorf("expected ErrorEvent attribute: %s", buffer.String())
+	}
+
+	var panicValues []interface{}
+	panicLogger, err := NewLogger(jsonHandler, &ledge.Specification{}, ledge.LoggerOptions{PanicFunc: func(value interface{}) { panicValues = append(panicValues, value) }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	panicLogger.Unstructured().Panic("panicked")
+	if len(panicValues) != 1 || panicValues[0] != "panicked" {
+		t.Errorf("expected panic with message, got %v", panicValues)
+	}
+}
+
+func TestHandlerLevels(t *testing.T) {
+	fakeLogger, err := ledge.NewFakeLogger(Specification)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slogLogger := slog.New(NewHandler(fakeLogger, HandlerOptions{Level: slog.LevelDebug, AddSource: true}))
+	slogLogger.Debug("debug")
+	slogLogger.Log(nil, slog.LevelInfo+2, "info")
+	slogLogger.Warn("warn")
+	slogLogger.Log(nil, slog.LevelError+4, "error")
+	slog.New(NewHandler(fakeLogger, HandlerOptions{})).Debug("disabled")
+
+	entries, err := fakeLogger.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedLevels := []ledge.Level{ledge.Level_DEBUG, ledge.Level_INFO, ledge.Level_WARN, ledge.Level_ERROR}
+	if len(entries) != len(expectedLevels) {
+		t.Fatalf("expected %d entries, got %d", len(expectedLevels), len(entries))
+	}
+	for i, entry := range entries {
+		if entry.Level != expectedLevels[i] {
+			t.Errorf("expected level %v, got %v", expectedLevels[i], entry.Level)
+		}
+		event := entry.Event.(*Event)
+		if event.Source == nil || event.Source.Function != "github.com/codeship/go-ledge/ledgeslog.TestHandlerLevels" {
+			t.Errorf("unexpected source %v", event.Source)
+		}
+	}
+	if level := entries[1].Event.(*Event).Level; level != int64(slog.LevelInfo+2) {
+		t.Errorf("expected slog level %d, got %d", slog.LevelInfo+2, level)
+	}
+	if record := NewRecord(entries[1]); record.Level != slog.LevelInfo+2 {
+		t.Errorf("expected record level %v, got %v", slog.LevelInfo+2, record.Level)
+	}
+}
+
+func TestAttrString(t *testing.T) {
+	event := &Event{
+		Msg: "msg",
+		Attrs: []Attr{
+			{"a", "b"},
+			{"G", []Attr{{"c", int64(1)}, {"H", []Attr{{"d", true}}}}},
+		},
+	}
+	if s := event.String(); s != "msg a=b G={c=1 H={d=true}}" {
+		t.Errorf("unexpected string %q", s)
+	}
+	data, err := json.Marshal(event.Attrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var attrs []Attr
+	if err := json.Unmarshal(data, &attrs); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(attrs, event.Attrs) {
+		t.Errorf("expected %v, got %v", event.Attrs, attrs)
+	}
+}
+
+func readEntries(t *testing.T, buffer *bytes.Buffer, unmarshaller ledge.Unmarshaller, decoder ledge.Decoder) []*ledge.Entry {
+	if decoder != nil {
+		entryReader, err := ledge.NewEntryReader(bytes.NewReader(buffer.Bytes()), unmarshaller, decoder, ledge.EntryReaderOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries, err := ledge.NewBlockingEntryReader(entryReader).Entries()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return entries
+	}
+	var entries []*ledge.Entry
+	scanner := bufio.NewScanner(bytes.NewReader(buffer.Bytes()))
+	for scanner.Scan() {
+		entry, err := unmarshaller.Unmarshal(scanner.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func recordToMap(record slog.Record) map[string]interface{} {
+	m := map[string]interface{}{
+		slog.LevelKey:   record.Level,
+		slog.MessageKey: record.Message,
+	}
+	if !record.Time.IsZero() {
+		m[slog.TimeKey] = record.Time
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		addAttrToMap(m, attr)
+		return true
+	})
+	return m
+}
+
+func addAttrToMap(m map[string]interface{}, attr slog.Attr) {
+	if attr.Value.Kind() != slog.KindGroup {
+		m[attr.Key] = attr.Value.Any()
+		return
+	}
+	group := make(map[string]interface{})
+	for _, groupAttr := range attr.Value.Group() {
+		addAttrToMap(group, groupAttr)
+	}
+	m[attr.Key] = group
+}
@@ -0,0 +1,255 @@
+package ledgeslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/codeship/go-ledge"
+)
+
+const (
+	idKey = "id"
+)
+
+type handlerMarshaller struct {
+	handler slog.Handler
+}
+
+func newHandlerMarshaller(
+	handler slog.Handler,
+) *handlerMarshaller {
+	return &handlerMarshaller{
+		handler,
+	}
+}
+
+// Marshal handles the Entry and returns the message of the slog.Record, which the Logger
+// writes to io.Discard, and panics with for Entry objects at the Panic Level.
+func (h *handlerMarshaller) Marshal(entry *ledge.Entry) ([]byte, error) {
+	record := newRecord(entry)
+	ctx := context.Background()
+	if !h.handler.Enabled(ctx, record.Level) {
+		return []byte(record.Message), nil
+	}
+	return []byte(record.Message), h.handler.Handle(ctx, record)
+}
+
+func newRecord(entry *ledge.Entry) slog.Record {
+	var record slog.Record
+	var source *ledge.Caller
+	var slogAttrs []slog.Attr
+	var attrs []Attr
+	event, isEvent := entry.Event.(*Event)
+	if isEvent {
+		record = slog.NewRecord(event.Time, slog.Level(event.Level), event.Msg, 0)
+		source = event.Source
+	} else {
+		record = slog.NewRecord(entry.Time, getSlogLevel(entry.Level), getMessage(entry), 0)
+		source = entry.Caller
+		slogAttrs = append(slogAttrs, slog.String(idKey, entry.ID))
+	}
+	if source != nil {
+		slogAttrs = append(slogAttrs, slog.Any(slog.SourceKey, &slog.Source{Function: source.Function, File: source.File, Line: source.Line}))
+	}
+	for _, context := range entry.Contexts {
+		if contextAttrs, ok := context.(*Attrs); ok {
+			attrs = mergeAttrs(attrs, contextAttrs.Attrs)
+		} else {
+			slogAttrs = append(slogAttrs, slog.Any(getTypeName(context), context))
+		}
+	}
+	if isEvent {
+		attrs = mergeAttrs(attrs, event.Attrs)
+	} else if unstructuredEvent, ok := entry.Event.(*ledge.UnstructuredEvent); ok {
+		for _, key := range sortedFieldKeys(unstructuredEvent.Fields) {
+			slogAttrs = append(slogAttrs, slog.Any(key, unstructuredEvent.Fields[key].Value()))
+		}
+	} else {
+		slogAttrs = append(slogAttrs, slog.Any(getTypeName(entry.Event), entry.Event))
+	}
+	for _, attr := range attrs {
+		slogAttrs = append(slogAttrs, getSlogAttr(attr))
+	}
+	record.AddAttrs(slogAttrs...)
+	return record
+}
+
+// mergeAttrs merges groups with the same key, so that attributes added with WithAttrs
+// and attributes of the slog.Record in the same group end up in one group.
+func mergeAttrs(attrs []Attr, newAttrs []Attr) []Attr {
+	for _, newAttr := range newAttrs {
+		newGroupAttrs, ok := newAttr.Value.([]Attr)
+		if !ok {
+			attrs = append(attrs, newAttr)
+			continue
+		}
+		merged := false
+		for i, attr := range attrs {
+			if groupAttrs, ok := attr.Value.([]Attr); ok && attr.Key == newAttr.Key {
+				attrs[i] = Attr{attr.Key, mergeAttrs(append([]Attr(nil), groupAttrs...), newGroupAttrs)}
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			attrs = append(attrs, newAttr)
+		}
+	}
+	return attrs
+}
+
+func getSlogAttr(attr Attr) slog.Attr {
+	switch value := attr.Value.(type) {
+	case string:
+		return slog.String(attr.Key, value)
+	case int64:
+		return slog.Int64(attr.Key, value)
+	case uint64:
+		return slog.Uint64(attr.Key, value)
+	case float64:
+		return slog.Float64(attr.Key, value)
+	case bool:
+		return slog.Bool(attr.Key, value)
+	case time.Duration:
+		return slog.Duration(attr.Key, value)
+	case time.Time:
+		return slog.Time(attr.Key, value)
+	case []Attr:
+		groupSlogAttrs := make([]slog.Attr, len(value))
+		for i, groupAttr := range value {
+			groupSlogAttrs[i] = getSlogAttr(groupAttr)
+		}
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(groupSlogAttrs...)}
+	default:
+		return slog.Any(attr.Key, value)
+	}
+}
+
+func sortedFieldKeys(fieldValues map[string]*ledge.FieldValue) []string {
+	keys := make([]string, 0, len(fieldValues))
+	for key := range fieldValues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func getSlogLevel(level ledge.Level) slog.Level {
+	switch level {
+	case ledge.Level_DEBUG:
+		return slog.LevelDebug
+	case ledge.Level_INFO:
+		return slog.LevelInfo
+	case ledge.Level_WARN:
+		return slog.LevelWarn
+	case ledge.Level_ERROR:
+		return slog.LevelError
+	case ledge.Level_FATAL:
+		return slog.LevelError + 4
+	case ledge.Level_PANIC:
+		return slog.LevelError + 8
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func getMessage(entry *ledge.Entry) string {
+	if msgEvent, ok := entry.Event.(interface {
+		GetMsg() string
+	}); ok {
+		return msgEvent.GetMsg()
+	}
+	if len(entry.WriterOutput) > 0 {
+		return strings.TrimSpace(string(entry.WriterOutput))
+	}
+	return getTypeName(entry.Event)
+}
+
+func getTypeName(object interface{}) string {
+	switch value := object.(type) {
+	case *ledge.RawContext:
+		return value.TypeName[strings.LastIndex(value.TypeName, ".")+1:]
+	case *ledge.RawEvent:
+		return value.TypeName[strings.LastIndex(value.TypeName, ".")+1:]
+	}
+	reflectType := reflect.TypeOf(object)
+	for reflectType != nil && reflectType.Kind() == reflect.Ptr {
+		reflectType = reflectType.Elem()
+	}
+	if reflectType == nil || reflectType.Name() == "" {
+		return fmt.Sprintf("%T", object)
+	}
+	return reflectType.Name()
+}
+
+// UnmarshalJSON restores group values as []Attr and whole numbers as int64.
+// Values read back from JSON are otherwise strings, float64 or bool.
+func (a *Attr) UnmarshalJSON(data []byte) error {
+	var jsonAttr struct {
+		Key   string
+		Value json.RawMessage
+	}
+	if err := json.Unmarshal(data, &jsonAttr); err != nil {
+		return err
+	}
+	a.Key = jsonAttr.Key
+	if len(jsonAttr.Value) > 0 && jsonAttr.Value[0] == '[' {
+		var groupAttrs []Attr
+		if err := json.Unmarshal(jsonAttr.Value, &groupAttrs); err != nil {
+			return err
+		}
+		a.Value = groupAttrs
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsonAttr.Value))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	if number, ok := value.(json.Number); ok {
+		if intValue, err := number.Int64(); err == nil {
+			value = intValue
+		} else if value, err = number.Float64(); err != nil {
+			return err
+		}
+	}
+	a.Value = value
+	return nil
+}
+
+// String returns the Attr as key=value, with groups as key={key=value ...}.
+func (a Attr) String() string {
+	if groupAttrs, ok := a.Value.([]Attr); ok {
+		return fmt.Sprintf("%s={%s}", a.Key, attrsString(groupAttrs))
+	}
+	return fmt.Sprintf("%s=%v", a.Key, a.Value)
+}
+
+// String returns the Attrs as key=value pairs.
+func (a *Attrs) String() string {
+	return attrsString(a.Attrs)
+}
+
+// String returns the message followed by the Attrs as key=value pairs.
+func (e *Event) String() string {
+	if len(e.Attrs) == 0 {
+		return e.Msg
+	}
+	return e.Msg + " " + attrsString(e.Attrs)
+}
+
+func attrsString(attrs []Attr) string {
+	attrStrings := make([]string, len(attrs))
+	for i, attr := range attrs {
+		attrStrings[i] = attr.String()
+	}
+	return strings.Join(attrStrings, " ")
+}
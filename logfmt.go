@@ -0,0 +1,661 @@
+package ledge
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	logfmtMsgKey        = "msg"
+	logfmtFieldsPrefix  = "fields."
+	logfmtCallerFile    = "caller.file"
+	logfmtCallerLine    = "caller.line"
+	logfmtCallerFunc    = "caller.function"
+	logfmtEmptyObject   = "{}"
+	logfmtPathSeparator = "."
+)
+
+var (
+	jsonUnmarshalerReflectType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerReflectType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+type logfmtMarshaller struct {
+	jsonKeys *jsonKeys
+	options  TextMarshallerOptions
+}
+
+func newLogfmtMarshaller(
+	options TextMarshallerOptions,
+) *logfmtMarshaller {
+	return &logfmtMarshaller{
+		defaultJSONKeys,
+		options,
+	}
+}
+
+func (l *logfmtMarshaller) Marshal(entry *Entry) ([]byte, error) {
+	buffer := bytes.NewBuffer(nil)
+	if !l.options.NoID {
+		if err := appendLogfmtPair(buffer, l.jsonKeys.id, entry.ID, true); err != nil {
+			return nil, err
+		}
+	}
+	if !l.options.NoTime {
+		if err := appendLogfmtPair(buffer, l.jsonKeys.time, entry.Time.UTC().Format(time.RFC3339Nano), true); err != nil {
+			return nil, err
+		}
+	}
+	if !l.options.NoLevel {
+		if err := appendLogfmtPair(buffer, l.jsonKeys.level, strings.ToLower(entry.Level.String()), true); err != nil {
+			return nil, err
+		}
+	}
+	eventKey, err := textMarshallerObjectKeyString(entry.Event)
+	if err != nil {
+		return nil, err
+	}
+	if err := appendLogfmtPair(buffer, l.jsonKeys.eventType, eventKey, true); err != nil {
+		return nil, err
+	}
+	if unstructuredEvent, ok := entry.Event.(*UnstructuredEvent); ok {
+		if err := l.appendUnstructuredEvent(buffer, unstructuredEvent); err != nil {
+			return nil, err
+		}
+	} else if err := appendLogfmtObject(buffer, eventKey, entry.Event); err != nil {
+		return nil, err
+	}
+	if !l.options.NoContexts {
+		for _, context := range entry.Contexts {
+			contextKey, err := textMarshallerObjectKeyString(context)
+			if err != nil {
+				return nil, err
+			}
+			if err := appendLogfmtObject(buffer, contextKey, context); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(entry.WriterOutput) > 0 {
+		if err := appendLogfmtPair(buffer, l.jsonKeys.writerOutput, string(entry.WriterOutput), true); err != nil {
+			return nil, err
+		}
+	}
+	if !l.options.NoCaller && entry.Caller != nil {
+		for _, pair := range [][2]string{
+			{logfmtCallerFile, entry.Caller.File},
+			{logfmtCallerLine, strconv.Itoa(entry.Caller.Line)},
+			{logfmtCallerFunc, entry.Caller.Function},
+		} {
+			if err := appendLogfmtPair(buffer, pair[0], pair[1], pair[0] != logfmtCallerLine); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !l.options.NoStack && entry.Stack != "" {
+		if err := appendLogfmtPair(buffer, l.jsonKeys.stack, entry.Stack, true); err != nil {
+			return nil, err
+		}
+	}
+	return buffer.Bytes(), nil
+}
+
+// appendUnstructuredEvent appends the message and every field as its own key prefixed with fields.,
+// since the Marshaller does not know the Specification and any other key could be read back as the
+// Entry itself or a Context. Characters that are not valid in a logfmt key are replaced with _.
+func (l *logfmtMarshaller) appendUnstructuredEvent(buffer *bytes.Buffer, unstructuredEvent *UnstructuredEvent) error {
+	if err := appendLogfmtPair(buffer, logfmtMsgKey, unstructuredEvent.Msg, true); err != nil {
+		return err
+	}
+	for _, key := range sortedFieldKeys(unstructuredEvent.Fields) {
+		if err := appendLogfmtFieldValue(buffer, logfmtFieldsPrefix+getLogfmtKey(key), unstructuredEvent.Fields[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendLogfmtFieldValue(buffer *bytes.Buffer, key string, fieldValue *FieldValue) error {
+	switch fieldValue.GetType() {
+	case FieldType_STRING:
+		return appendLogfmtPair(buffer, key, fieldValue.GetStringValue(), true)
+	case FieldType_INT:
+		return appendLogfmtPair(buffer, key, strconv.FormatInt(fieldValue.GetIntValue(), 10), false)
+	case FieldType_FLOAT:
+		value := strconv.FormatFloat(fieldValue.GetFloatValue(), 'g', -1, 64)
+		// keep whole floats apart from ints when read back
+		if !strings.ContainsAny(value, ".eEIN") {
+			value += ".0"
+		}
+		return appendLogfmtPair(buffer, key, value, false)
+	case FieldType_BOOL:
+		return appendLogfmtPair(buffer, key, strconv.FormatBool(fieldValue.GetBoolValue()), false)
+	default:
+		return appendLogfmtPair(buffer, key, fieldValueString(fieldValue), true)
+	}
+}
+
+// appendLogfmtObject appends the JSON of the object flattened into key.path=value pairs.
+func appendLogfmtObject(buffer *bytes.Buffer, key string, object interface{}) error {
+	if typeName, format, data, ok := rawObject(object); ok {
+		if format != RawFormatJSON {
+			return fmt.Errorf("ledge: cannot marshal raw %s of format %d with a logfmt Marshaller", typeName, format)
+		}
+		return appendLogfmtJSON(buffer, key, data)
+	}
+	data, err := json.Marshal(object)
+	if err != nil {
+		return err
+	}
+	return appendLogfmtJSON(buffer, key, data)
+}
+
+func appendLogfmtJSON(buffer *bytes.Buffer, key string, data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil
+	}
+	switch data[0] {
+	case '{':
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		if _, err := decoder.Token(); err != nil {
+			return err
+		}
+		empty := true
+		for decoder.More() {
+			token, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			objectKey, ok := token.(string)
+			if !ok || strings.Contains(objectKey, logfmtPathSeparator) {
+				return fmt.Errorf("ledge: cannot marshal key %v of %s with a logfmt Marshaller", token, key)
+			}
+			var value json.RawMessage
+			if err := decoder.Decode(&value); err != nil {
+				return err
+			}
+			if err := appendLogfmtJSON(buffer, key+logfmtPathSeparator+objectKey, value); err != nil {
+				return err
+			}
+			empty = false
+		}
+		if empty {
+			return appendLogfmtPair(buffer, key, logfmtEmptyObject, false)
+		}
+		return nil
+	case '"':
+		var value string
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		return appendLogfmtPair(buffer, key, value, true)
+	case 'n':
+		// null values are left out and unmarshalled as zero values
+		return nil
+	default:
+		return appendLogfmtPair(buffer, key, string(data), false)
+	}
+}
+
+// appendLogfmtPair appends key=value, quoting the value if needed. String values that would
+// otherwise read as another JSON value, such as "1" or "true", are quoted as well.
+func appendLogfmtPair(buffer *bytes.Buffer, key string, value string, isString bool) error {
+	if !isValidLogfmtKey(key) {
+		return fmt.Errorf("ledge: invalid logfmt key %q", key)
+	}
+	if buffer.Len() > 0 {
+		buffer.WriteByte(' ')
+	}
+	buffer.WriteString(key)
+	buffer.WriteByte('=')
+	if needsLogfmtQuote(value) || (isString && json.Valid([]byte(value))) {
+		buffer.WriteString(strconv.Quote(value))
+	} else {
+		buffer.WriteString(value)
+	}
+	return nil
+}
+
+func isValidLogfmtKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !isValidLogfmtKeyRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// getLogfmtKey returns key with characters that are not valid in a logfmt key replaced with _.
+func getLogfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if !isValidLogfmtKeyRune(r) {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+func isValidLogfmtKeyRune(r rune) bool {
+	return r > ' ' && r != '=' && r != '"' && r != utf8.RuneError && unicode.IsPrint(r)
+}
+
+func needsLogfmtQuote(value string) bool {
+	if value == "" {
+		return true
+	}
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
+
+type logfmtPair struct {
+	key    string
+	value  string
+	quoted bool
+}
+
+func parseLogfmt(data []byte) ([]*logfmtPair, error) {
+	s := strings.TrimRight(string(data), "\r\n")
+	var pairs []*logfmtPair
+	for i := 0; i < len(s); {
+		if s[i] == ' ' || s[i] == '\t' {
+			i++
+			continue
+		}
+		start := i
+		for i < len(s) && s[i] != '=' && s[i] != ' ' && s[i] != '\t' && s[i] != '"' {
+			i++
+		}
+		if i == start {
+			return nil, fmt.Errorf("ledge: expected logfmt key at position %d in %s", i, s)
+		}
+		pair := &logfmtPair{key: s[start:i]}
+		if i < len(s) && s[i] == '=' {
+			i++
+			if i < len(s) && s[i] == '"' {
+				end := i + 1
+				for ; end < len(s) && s[end] != '"'; end++ {
+					if s[end] == '\\' {
+						end++
+					}
+				}
+				if end >= len(s) {
+					return nil, fmt.Errorf("ledge: unterminated logfmt value at position %d in %s", i, s)
+				}
+				value, err := strconv.Unquote(s[i : end+1])
+				if err != nil {
+					return nil, fmt.Errorf("ledge: invalid logfmt value at position %d in %s: %s", i, s, err.Error())
+				}
+				pair.value = value
+				pair.quoted = true
+				i = end + 1
+			} else {
+				start = i
+				for i < len(s) && s[i] != ' ' && s[i] != '\t' {
+					i++
+				}
+				pair.value = s[start:i]
+			}
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, nil
+}
+
+// logfmtNode is the tree of the key.path=value pairs of one Context or Event.
+type logfmtNode struct {
+	pair     *logfmtPair
+	keys     []string
+	children map[string]*logfmtNode
+}
+
+func newLogfmtNode() *logfmtNode {
+	return &logfmtNode{
+		nil,
+		nil,
+		make(map[string]*logfmtNode),
+	}
+}
+
+// add returns false if the path is already set or conflicts with another path.
+func (l *logfmtNode) add(path []string, pair *logfmtPair) bool {
+	if len(path) == 0 {
+		if l.pair != nil || len(l.keys) > 0 {
+			return false
+		}
+		l.pair = pair
+		return true
+	}
+	if l.pair != nil {
+		return false
+	}
+	child, ok := l.children[path[0]]
+	if !ok {
+		child = newLogfmtNode()
+		if !child.add(path[1:], pair) {
+			return false
+		}
+		l.keys = append(l.keys, path[0])
+		l.children[path[0]] = child
+		return true
+	}
+	return child.add(path[1:], pair)
+}
+
+type logfmtGroup struct {
+	name  string
+	node  *logfmtNode
+	pairs []*logfmtPair
+}
+
+// addLogfmtGroupPair adds the pair to the last group with the same name, or starts a new group
+// if the key is already set, so that repeated Context types are read as separate Contexts.
+func addLogfmtGroupPair(groups []*logfmtGroup, pair *logfmtPair) []*logfmtGroup {
+	path := strings.Split(pair.key, logfmtPathSeparator)
+	for i := len(groups) - 1; i >= 0; i-- {
+		if groups[i].name == path[0] {
+			if groups[i].node.add(path[1:], pair) {
+				groups[i].pairs = append(groups[i].pairs, pair)
+				return groups
+			}
+			break
+		}
+	}
+	node := newLogfmtNode()
+	node.add(path[1:], pair)
+	return append(groups, &logfmtGroup{path[0], node, []*logfmtPair{pair}})
+}
+
+type logfmtUnmarshaller struct {
+	reflectTypeProvider *reflectTypeProvider
+	jsonKeys            *jsonKeys
+	options             UnmarshallerOptions
+}
+
+func newLogfmtUnmarshaller(
+	specification *Specification,
+	options UnmarshallerOptions,
+) (*logfmtUnmarshaller, error) {
+	reflectTypeProvider, err := newReflectTypeProvider(specification)
+	if err != nil {
+		return nil, err
+	}
+	return &logfmtUnmarshaller{
+		reflectTypeProvider,
+		defaultJSONKeys,
+		options,
+	}, nil
+}
+
+func (l *logfmtUnmarshaller) Unmarshal(buffer []byte) (*Entry, error) {
+	pairs, err := parseLogfmt(buffer)
+	if err != nil {
+		return nil, err
+	}
+	entry := &Entry{
+		Contexts: make([]Context, 0),
+	}
+	var eventKey string
+	var msg string
+	var groups []*logfmtGroup
+	for _, pair := range pairs {
+		switch pair.key {
+		case l.jsonKeys.id:
+			entry.ID = pair.value
+		case l.jsonKeys.time:
+			entryTime, err := time.Parse(time.RFC3339Nano, pair.value)
+			if err != nil {
+				return nil, err
+			}
+			entry.Time = entryTime.UTC()
+		case l.jsonKeys.level:
+			level, ok := Level_value[strings.ToUpper(pair.value)]
+			if !ok {
+				return nil, fmt.Errorf("ledge: unknown level %s", pair.value)
+			}
+			entry.Level = Level(level)
+		case l.jsonKeys.eventType:
+			eventKey = pair.value
+		case logfmtMsgKey:
+			msg = pair.value
+		case l.jsonKeys.writerOutput:
+			entry.WriterOutput = []byte(pair.value)
+		case l.jsonKeys.stack:
+			entry.Stack = pair.value
+		case logfmtCallerFile, logfmtCallerLine, logfmtCallerFunc:
+			if entry.Caller == nil {
+				entry.Caller = &Caller{}
+			}
+			switch pair.key {
+			case logfmtCallerFile:
+				entry.Caller.File = pair.value
+			case logfmtCallerLine:
+				line, err := strconv.Atoi(pair.value)
+				if err != nil {
+					return nil, err
+				}
+				entry.Caller.Line = line
+			default:
+				entry.Caller.Function = pair.value
+			}
+		default:
+			groups = addLogfmtGroupPair(groups, pair)
+		}
+	}
+	if eventKey == "" {
+		return nil, fmt.Errorf("ledge: no %s key in %s", l.jsonKeys.eventType, string(buffer))
+	}
+	eventReflectType, err := l.reflectTypeProvider.getEventReflectTypeForShortKey(eventKey)
+	if err != nil && !isLenientError(l.options, err) {
+		return nil, err
+	}
+	var unstructuredEvent *UnstructuredEvent
+	if eventReflectType == reflect.TypeOf(&UnstructuredEvent{}) {
+		unstructuredEvent = &UnstructuredEvent{Msg: msg}
+		entry.Event = unstructuredEvent
+	}
+	for _, group := range groups {
+		if entry.Event == nil && group.name == eventKey {
+			event, err := l.getObject(eventKey, eventReflectType, group.node, false)
+			if err != nil {
+				return nil, err
+			}
+			entry.Event = event
+			continue
+		}
+		contextReflectType, err := l.reflectTypeProvider.getContextReflectTypeForShortKey(group.name)
+		if err != nil {
+			// keys that are not Context types are the fields of an UnstructuredEvent
+			if _, ok := err.(*noReflectTypeError); ok && unstructuredEvent != nil {
+				l.addFields(unstructuredEvent, group)
+				continue
+			}
+			if !isLenientError(l.options, err) {
+				return nil, err
+			}
+		}
+		context, err := l.getObject(group.name, contextReflectType, group.node, true)
+		if err != nil {
+			return nil, err
+		}
+		entry.Contexts = append(entry.Contexts, context)
+	}
+	if entry.Event == nil {
+		// an Event with no values left to write
+		event, err := l.getObject(eventKey, eventReflectType, nil, false)
+		if err != nil {
+			return nil, err
+		}
+		entry.Event = event
+	}
+	return entry, nil
+}
+
+// getObject returns a *RawContext or *RawEvent with the JSON of the node if there is no reflect.Type.
+func (l *logfmtUnmarshaller) getObject(name string, reflectType reflect.Type, node *logfmtNode, isContext bool) (interface{}, error) {
+	data, err := getLogfmtJSON(reflectType, node)
+	if err != nil {
+		return nil, err
+	}
+	if reflectType == nil {
+		if isContext {
+			return &RawContext{name, RawFormatJSON, data}, nil
+		}
+		return &RawEvent{name, RawFormatJSON, data}, nil
+	}
+	return getJSONObject(reflectType, data)
+}
+
+func (l *logfmtUnmarshaller) addFields(unstructuredEvent *UnstructuredEvent, group *logfmtGroup) {
+	if unstructuredEvent.Fields == nil {
+		unstructuredEvent.Fields = make(map[string]*FieldValue)
+	}
+	for _, pair := range group.pairs {
+		unstructuredEvent.Fields[strings.TrimPrefix(pair.key, logfmtFieldsPrefix)] = getLogfmtFieldValue(pair)
+	}
+}
+
+// getLogfmtFieldValue reads quoted values as strings. Times, durations and bytes are read as strings.
+func getLogfmtFieldValue(pair *logfmtPair) *FieldValue {
+	if !pair.quoted {
+		if value, err := strconv.ParseInt(pair.value, 10, 64); err == nil {
+			return &FieldValue{Type: FieldType_INT, IntValue: value}
+		}
+		if strings.ContainsAny(pair.value, ".eE") {
+			if value, err := strconv.ParseFloat(pair.value, 64); err == nil {
+				return &FieldValue{Type: FieldType_FLOAT, FloatValue: value}
+			}
+		}
+		if value, err := strconv.ParseBool(pair.value); err == nil && (pair.value == "true" || pair.value == "false") {
+			return &FieldValue{Type: FieldType_BOOL, BoolValue: value}
+		}
+	}
+	return &FieldValue{Type: FieldType_STRING, StringValue: pair.value}
+}
+
+// getLogfmtJSON returns the JSON for the node, using the reflect.Type it will be unmarshalled into
+// to tell strings from other values. Without a reflect.Type, unquoted values that are valid JSON
+// are used as is and anything else is a string.
+func getLogfmtJSON(reflectType reflect.Type, node *logfmtNode) ([]byte, error) {
+	if node == nil {
+		return []byte("null"), nil
+	}
+	for reflectType != nil && reflectType.Kind() == reflect.Ptr {
+		reflectType = reflectType.Elem()
+	}
+	if node.pair != nil {
+		return getLogfmtValueJSON(reflectType, node.pair)
+	}
+	buffer := bytes.NewBuffer(nil)
+	buffer.WriteByte('{')
+	for i, key := range node.keys {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		keyData, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(keyData)
+		buffer.WriteByte(':')
+		data, err := getLogfmtJSON(getLogfmtChildReflectType(reflectType, key), node.children[key])
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(data)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+func getLogfmtValueJSON(reflectType reflect.Type, pair *logfmtPair) ([]byte, error) {
+	if reflectType == nil || reflectType.Kind() == reflect.Interface || reflect.PtrTo(reflectType).Implements(jsonUnmarshalerReflectType) {
+		if !pair.quoted && json.Valid([]byte(pair.value)) {
+			return []byte(pair.value), nil
+		}
+		return json.Marshal(pair.value)
+	}
+	if reflect.PtrTo(reflectType).Implements(textUnmarshalerReflectType) {
+		return json.Marshal(pair.value)
+	}
+	switch reflectType.Kind() {
+	case reflect.String:
+		return json.Marshal(pair.value)
+	case reflect.Slice:
+		// []byte is marshalled as a base64 string
+		if reflectType.Elem().Kind() == reflect.Uint8 {
+			return json.Marshal(pair.value)
+		}
+	}
+	return []byte(pair.value), nil
+}
+
+func getLogfmtChildReflectType(reflectType reflect.Type, key string) reflect.Type {
+	if reflectType == nil {
+		return nil
+	}
+	switch reflectType.Kind() {
+	case reflect.Map:
+		return reflectType.Elem()
+	case reflect.Struct:
+		return getJSONFieldReflectType(reflectType, key)
+	default:
+		return nil
+	}
+}
+
+// getJSONFieldReflectType returns the reflect.Type of the struct field encoding/json uses for the key.
+func getJSONFieldReflectType(reflectType reflect.Type, key string) reflect.Type {
+	var foldReflectType reflect.Type
+	for i := 0; i < reflectType.NumField(); i++ {
+		field := reflectType.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			embeddedReflectType := field.Type
+			if embeddedReflectType.Kind() == reflect.Ptr {
+				embeddedReflectType = embeddedReflectType.Elem()
+			}
+			if embeddedReflectType.Kind() == reflect.Struct {
+				if fieldReflectType := getJSONFieldReflectType(embeddedReflectType, key); fieldReflectType != nil {
+					return fieldReflectType
+				}
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if name == key {
+			return field.Type
+		}
+		if foldReflectType == nil && strings.EqualFold(name, key) {
+			foldReflectType = field.Type
+		}
+	}
+	return foldReflectType
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"reflect"
+	"runtime/debug"
 	"time"
 )
 
@@ -13,7 +14,9 @@ type logger struct {
 	marshaller          Marshaller
 	reflectTypeProvider *reflectTypeProvider
 	options             LoggerOptions
+	name                string
 	contexts            []Context
+	asyncWriter         *asyncWriter
 }
 
 func newLogger(
@@ -21,14 +24,18 @@ func newLogger(
 	marshaller Marshaller,
 	reflectTypeProvider *reflectTypeProvider,
 	opts LoggerOptions,
+	name string,
 	contexts []Context,
+	asyncWriter *asyncWriter,
 ) *logger {
 	return &logger{
 		writer,
 		marshaller,
 		reflectTypeProvider,
 		opts,
+		name,
 		contexts,
+		asyncWriter,
 	}
 }
 
@@ -41,12 +48,29 @@ func (l *logger) WithContext(context Context) Logger {
 		l.marshaller,
 		l.reflectTypeProvider,
 		l.options,
+		l.name,
 		append(l.contexts, context),
+		l.asyncWriter,
+	)
+}
+
+func (l *logger) Named(name string) Logger {
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	return newLogger(
+		l.writer,
+		l.marshaller,
+		l.reflectTypeProvider,
+		l.options,
+		name,
+		l.contexts,
+		l.asyncWriter,
 	)
 }
 
 func (l *logger) Unstructured() UnstructuredLogger {
-	return newUnstructuredLogger(l, make(map[string]interface{}))
+	return newUnstructuredLogger(l, nil)
 }
 
 func (l *logger) Debug(event Event) {
@@ -91,7 +115,7 @@ func (l *logger) WarnWriter(event Event) io.Writer {
 
 func (l *logger) ErrorEvent(err error) {
 	if err != nil {
-		l.Error(&ErrorEvent{err.Error()})
+		l.Error(newErrorEvent(err))
 	}
 }
 
@@ -112,6 +136,14 @@ func (l *logger) print(level Level, event Event) {
 	}
 }
 
+// writeQueuedEntry is called from the asyncWriter goroutine, where there is
+// no caller to panic to, so errors go to the BackupWriter or os.Stderr.
+func (l *logger) writeQueuedEntry(entry *Entry) {
+	if _, err := l.writeEntry(entry); err != nil {
+		l.writeError(err)
+	}
+}
+
 func (l *logger) printWriter(level Level, event Event) io.Writer {
 	if err := l.reflectTypeProvider.validateEventReflectType(reflect.TypeOf(event)); err != nil {
 		panic(err.Error())
@@ -138,6 +170,17 @@ func (l *logger) getEntry(baseEntry *Entry, writerOutput []byte) *Entry {
 	}
 }
 
+// addCallerAndStack is only called once an Entry is known to be written, since walking
+// the stack is expensive for Entry objects that are filtered or sampled out.
+func (l *logger) addCallerAndStack(entry *Entry) {
+	if l.options.IncludeCaller {
+		entry.Caller = getCaller()
+	}
+	if l.options.StackTraceLevel != Level_NONE && entry.Level >= l.options.StackTraceLevel {
+		entry.Stack = string(debug.Stack())
+	}
+}
+
 func (l *logger) allocateID() string {
 	if l.options.IDAllocator != nil {
 		return l.options.IDAllocator.Allocate()
@@ -153,32 +196,103 @@ func (l *logger) now() time.Time {
 }
 
 func (l *logger) write(entry *Entry) (int, error) {
+	if entry.Level >= Level_FATAL {
+		return l.writeFinal(entry)
+	}
+	if !l.include(entry) {
+		return 0, nil
+	}
+	l.addCallerAndStack(entry)
+	if l.asyncWriter != nil {
+		l.asyncWriter.enqueue(entry)
+		return 0, nil
+	}
+	return l.writeEntry(entry)
+}
+
+// writeFinal writes Fatal and Panic Entry objects, which are never filtered, and then
+// exits or panics. Errors are reported to the BackupWriter or os.Stderr, since the
+// exit or panic has to happen regardless.
+func (l *logger) writeFinal(entry *Entry) (int, error) {
+	var n int
+	l.addCallerAndStack(entry)
+	// everything queued has to be written first
+	if l.asyncWriter != nil {
+		if err := l.asyncWriter.flush(0); err != nil {
+			l.writeError(err)
+		}
+	}
 	p, err := l.marshaller.Marshal(entry)
 	if err != nil {
-		return 0, err
+		l.writeError(err)
+		p = []byte(err.Error())
+	} else {
+		n = l.writeFinalMarshalled(p)
 	}
-	// TODO(pedge): does this work?
 	if entry.Level == Level_PANIC {
-		panic(string(p))
-	}
-	if l.include(entry) {
-		if l.options.Encoder != nil {
-			return l.options.Encoder.Encode(l.writer, p)
+		if l.options.PanicFunc != nil {
+			l.options.PanicFunc(string(p))
+		} else {
+			panic(string(p))
 		}
-		q, err := l.addNewline(p)
-		if err != nil {
-			return 0, err
+	} else {
+		if l.options.ExitFunc != nil {
+			l.options.ExitFunc(1)
+		} else {
+			os.Exit(1)
 		}
-		return l.writer.Write(q)
 	}
-	// TODO(pedge): does this work?
-	if entry.Level == Level_FATAL {
-		os.Exit(1)
+	return n, nil
+}
+
+// writeFinalMarshalled writes and syncs p, holding the asyncWriter write lock since its
+// goroutine keeps writing anything queued after the flush.
+func (l *logger) writeFinalMarshalled(p []byte) int {
+	if l.asyncWriter != nil {
+		l.asyncWriter.writeLock.Lock()
+		defer l.asyncWriter.writeLock.Unlock()
+	}
+	n, err := l.writeMarshalled(p)
+	if err != nil {
+		l.writeError(err)
+	}
+	if err := syncWriter(l.writer); err != nil {
+		l.writeError(err)
+	}
+	return n
+}
+
+func (l *logger) writeEntry(entry *Entry) (int, error) {
+	p, err := l.marshaller.Marshal(entry)
+	if err != nil {
+		return 0, err
+	}
+	return l.writeMarshalled(p)
+}
+
+func (l *logger) writeMarshalled(p []byte) (int, error) {
+	if l.options.Encoder != nil {
+		return l.options.Encoder.Encode(l.writer, p)
+	}
+	q, err := l.addNewline(p)
+	if err != nil {
+		return 0, err
+	}
+	return l.writer.Write(q)
+}
+
+func (l *logger) writeError(err error) {
+	backupWriter := l.options.BackupWriter
+	if backupWriter == nil {
+		backupWriter = os.Stderr
 	}
-	return 0, nil
+	_, _ = backupWriter.Write([]byte(err.Error()))
 }
 
 func (l *logger) include(entry *Entry) bool {
+	if l.options.LevelRegistry != nil && !l.options.LevelRegistry.AtomicLevel(l.name).Include(entry) {
+		return false
+	}
 	return includeEntry(l.options.Filters, entry)
 }
 
@@ -192,3 +306,19 @@ func (l *logger) addNewline(p []byte) ([]byte, error) {
 	}
 	return buffer.Bytes(), nil
 }
+
+// syncWriter flushes writers that buffer, such as *os.File or *bufio.Writer.
+func syncWriter(writer io.Writer) error {
+	switch writer := writer.(type) {
+	case interface {
+		Sync() error
+	}:
+		// Sync fails for pipes and terminals, which have nothing to sync
+		_ = writer.Sync()
+	case interface {
+		Flush() error
+	}:
+		return writer.Flush()
+	}
+	return nil
+}
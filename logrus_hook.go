@@ -0,0 +1,45 @@
+package ledge
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+type logrusHook struct {
+	unstructuredLogger UnstructuredLogger
+}
+
+func newLogrusHook(
+	unstructuredLogger UnstructuredLogger,
+) *logrusHook {
+	return &logrusHook{
+		unstructuredLogger,
+	}
+}
+
+func (l *logrusHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (l *logrusHook) Fire(entry *logrus.Entry) error {
+	level, err := getLogrusHookLevel(entry.Level)
+	if err != nil {
+		return err
+	}
+	logUnstructured(l.unstructuredLogger.WithFields(Fields(entry.Data)), level, entry.Message)
+	return nil
+}
+
+func getLogrusHookLevel(logrusLevel logrus.Level) (Level, error) {
+	// logrus has a Trace Level below Debug
+	if logrusLevel == logrus.TraceLevel {
+		return Level_DEBUG, nil
+	}
+	for level, levelLogrusLevel := range levelToLogrusLevel {
+		if levelLogrusLevel == logrusLevel {
+			return level, nil
+		}
+	}
+	return Level_NONE, fmt.Errorf("ledge: no Level for logrus Level %v", logrusLevel)
+}
@@ -9,6 +9,7 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/nsf/termbox-go"
@@ -28,6 +29,8 @@ var (
 		level:        "level",
 		eventType:    "event_type",
 		writerOutput: "writer_output",
+		caller:       "caller",
+		stack:        "stack",
 	}
 	levelToLogrusLevel = map[Level]logrus.Level{
 		Level_DEBUG: logrus.DebugLevel,
@@ -37,7 +40,8 @@ var (
 		Level_FATAL: logrus.FatalLevel,
 		Level_PANIC: logrus.PanicLevel,
 	}
-	protoMarshallerInstance = &protoMarshaller{}
+	protoMarshallerInstance       = &protoMarshaller{false}
+	binaryProtoMarshallerInstance = &protoMarshaller{true}
 )
 
 type logrusTextMarshaller struct {
@@ -87,6 +91,12 @@ func (l *logrusTextMarshaller) Marshal(entry *Entry) ([]byte, error) {
 		return nil, err
 	}
 	logrusEntry.Data[eventKeyString] = textMarshallerObjectValueString(entry.Event)
+	if !l.options.NoCaller && entry.Caller != nil {
+		logrusEntry.Data["caller"] = shortCallerString(entry.Caller)
+	}
+	if !l.options.NoStack && entry.Stack != "" {
+		logrusEntry.Data["stack"] = entry.Stack
+	}
 	data, err := logrusTextFormatter.Format(logrusEntry)
 	if err != nil {
 		return nil, err
@@ -154,7 +164,7 @@ func (t *textMarshallerV3) Marshal(entry *Entry) ([]byte, error) {
 		}
 		lines = append(lines, fmt.Sprintf("%s | %s", colorBlue(fieldsLine), writerOutputLine))
 	}
-	return []byte(strings.Join(lines, "\n")), nil
+	return appendTextStack([]byte(strings.Join(lines, "\n")), entry, t.options), nil
 }
 
 type textMarshallerV2 struct {
@@ -207,7 +217,7 @@ func (t *textMarshallerV2) Marshal(entry *Entry) ([]byte, error) {
 	if _, err := buffer.Write(fields); err != nil {
 		return nil, err
 	}
-	return buffer.Bytes(), nil
+	return appendTextStack(buffer.Bytes(), entry, t.options), nil
 }
 
 type textMarshaller struct {
@@ -246,7 +256,7 @@ func (t *textMarshaller) Marshal(entry *Entry) ([]byte, error) {
 			return nil, err
 		}
 	}
-	return trimRightSpaceBytes(buffer.Bytes()), nil
+	return appendTextStack(trimRightSpaceBytes(buffer.Bytes()), entry, t.options), nil
 }
 
 func textMarshallerFields(entry *Entry, options TextMarshallerOptions) ([]byte, error) {
@@ -266,6 +276,11 @@ func textMarshallerFields(entry *Entry, options TextMarshallerOptions) ([]byte,
 			return nil, err
 		}
 	}
+	if !options.NoCaller && entry.Caller != nil {
+		if _, err := buffer.WriteString(fmt.Sprintf("caller=%s ", shortCallerString(entry.Caller))); err != nil {
+			return nil, err
+		}
+	}
 	if !options.NoContexts {
 		for _, context := range entry.Contexts {
 			contextString, err := textMarshallerObjectString(context)
@@ -287,6 +302,24 @@ func textMarshallerFields(entry *Entry, options TextMarshallerOptions) ([]byte,
 	return buffer.Bytes(), nil
 }
 
+// appendTextStack appends the stack traces of the Entry and of an *ErrorEvent on the lines after the Entry.
+func appendTextStack(p []byte, entry *Entry, options TextMarshallerOptions) []byte {
+	if options.NoStack {
+		return p
+	}
+	var stacks []string
+	if entry.Stack != "" {
+		stacks = append(stacks, entry.Stack)
+	}
+	if errorEvent, ok := entry.Event.(*ErrorEvent); ok {
+		stacks = append(stacks, errorEventStacks(errorEvent)...)
+	}
+	for _, stack := range stacks {
+		p = append(append(p, '\n'), trimRightSpace(stack)...)
+	}
+	return p
+}
+
 func textMarshallerObjectString(object interface{}) (string, error) {
 	keyString, err := textMarshallerObjectKeyString(object)
 	if err != nil {
@@ -296,10 +329,25 @@ func textMarshallerObjectString(object interface{}) (string, error) {
 }
 
 func textMarshallerObjectKeyString(object interface{}) (string, error) {
+	if typeName, _, _, ok := rawObject(object); ok {
+		return shortTypeName(typeName), nil
+	}
 	return shortReflectKey(reflect.TypeOf(object))
 }
 
 func textMarshallerObjectValueString(object interface{}) string {
+	if errorEvent, ok := object.(*ErrorEvent); ok {
+		return errorEventString(errorEvent)
+	}
+	if unstructuredEvent, ok := object.(*UnstructuredEvent); ok && len(unstructuredEvent.Fields) > 0 {
+		return unstructuredEventString(unstructuredEvent)
+	}
+	if _, format, data, ok := rawObject(object); ok {
+		if format == RawFormatJSON {
+			return string(data)
+		}
+		return base64.StdEncoding.EncodeToString(data)
+	}
 	if stringer, ok := object.(fmt.Stringer); ok {
 		return trimRightSpace(stringer.String())
 	}
@@ -322,42 +370,166 @@ type jsonKeys struct {
 	level        string
 	eventType    string
 	writerOutput string
+	caller       string
+	stack        string
+}
+
+// newJSONKeys returns the keys with the default keys for keys that are not set.
+func newJSONKeys(keys JSONKeys) *jsonKeys {
+	return &jsonKeys{
+		getJSONKey(keys.ID, defaultJSONKeys.id),
+		getJSONKey(keys.Time, defaultJSONKeys.time),
+		getJSONKey(keys.Level, defaultJSONKeys.level),
+		getJSONKey(keys.EventType, defaultJSONKeys.eventType),
+		getJSONKey(keys.WriterOutput, defaultJSONKeys.writerOutput),
+		getJSONKey(keys.Caller, defaultJSONKeys.caller),
+		getJSONKey(keys.Stack, defaultJSONKeys.stack),
+	}
+}
+
+func getJSONKey(key string, defaultKey string) string {
+	if key == "" {
+		return defaultKey
+	}
+	return key
+}
+
+func formatJSONTime(t time.Time, options JSONMarshallerOptions) interface{} {
+	if options.TimeFormat == JSONTimeFormatUnixNano {
+		return t.UnixNano()
+	}
+	return t.Format(getJSONTimeLayout(options))
+}
+
+func getJSONTimeLayout(options JSONMarshallerOptions) string {
+	switch options.TimeFormat {
+	case JSONTimeFormatRFC3339Nano:
+		return time.RFC3339Nano
+	case JSONTimeFormatRFC3339:
+		return time.RFC3339
+	case JSONTimeFormatLayout:
+		return options.TimeLayout
+	default:
+		return timeFormat
+	}
+}
+
+// validateJSONMarshallerOptions returns an error if the time format is unknown, the layout is not
+// set for JSONTimeFormatLayout, or two keys are the same, since one value would overwrite the other.
+func validateJSONMarshallerOptions(options JSONMarshallerOptions) error {
+	switch options.TimeFormat {
+	case JSONTimeFormatDefault, JSONTimeFormatRFC3339Nano, JSONTimeFormatUnixNano, JSONTimeFormatRFC3339:
+	case JSONTimeFormatLayout:
+		if options.TimeLayout == "" {
+			return fmt.Errorf("ledge: no time layout for JSONTimeFormatLayout")
+		}
+	default:
+		return fmt.Errorf("ledge: unknown JSONTimeFormat %d", options.TimeFormat)
+	}
+	jsonKeys := newJSONKeys(options.Keys)
+	keys := []string{
+		jsonKeys.id,
+		jsonKeys.time,
+		jsonKeys.level,
+		jsonKeys.eventType,
+		jsonKeys.writerOutput,
+		jsonKeys.caller,
+		jsonKeys.stack,
+	}
+	if options.ContextsKey != "" {
+		keys = append(keys, options.ContextsKey)
+	}
+	keySet := make(map[string]bool)
+	for _, key := range keys {
+		if keySet[key] {
+			return fmt.Errorf("ledge: duplicate JSON key %s", key)
+		}
+		keySet[key] = true
+	}
+	return nil
+}
+
+func formatJSONLevel(level Level, options JSONMarshallerOptions) string {
+	if options.UpperCaseLevel {
+		return level.String()
+	}
+	return strings.ToLower(level.String())
 }
 
 type jsonMarshaller struct {
 	jsonKeys *jsonKeys
+	options  JSONMarshallerOptions
 }
 
 func newJSONMarshaller(
-	jsonKeys *jsonKeys,
+	options JSONMarshallerOptions,
 ) *jsonMarshaller {
 	return &jsonMarshaller{
-		jsonKeys,
+		newJSONKeys(options.Keys),
+		options,
 	}
 }
 
 func (j *jsonMarshaller) Marshal(entry *Entry) ([]byte, error) {
 	m := make(map[string]interface{})
 	m[j.jsonKeys.id] = entry.ID
-	m[j.jsonKeys.time] = entry.Time.Format(timeFormat)
-	m[j.jsonKeys.level] = strings.ToLower(entry.Level.String())
+	m[j.jsonKeys.time] = formatJSONTime(entry.Time, j.options)
+	m[j.jsonKeys.level] = formatJSONLevel(entry.Level, j.options)
+	contextsMap := m
+	if j.options.ContextsKey != "" && len(entry.Contexts) > 0 {
+		contextsMap = make(map[string]interface{})
+		m[j.options.ContextsKey] = contextsMap
+	}
 	for _, context := range entry.Contexts {
-		contextKey, err := shortReflectKey(reflect.TypeOf(context))
+		contextKey, contextValue, err := j.getKeyValue(context)
 		if err != nil {
 			return nil, err
 		}
-		m[contextKey] = context
+		contextsMap[contextKey] = contextValue
 	}
-	eventKey, err := shortReflectKey(reflect.TypeOf(entry.Event))
+	eventKey, eventValue, err := j.getKeyValue(entry.Event)
 	if err != nil {
 		return nil, err
 	}
 	m[j.jsonKeys.eventType] = eventKey
-	m[eventKey] = entry.Event
-	m[j.jsonKeys.writerOutput] = string(entry.WriterOutput)
+	m[eventKey] = eventValue
+	if len(entry.WriterOutput) > 0 || !j.options.OmitEmptyWriterOutput {
+		m[j.jsonKeys.writerOutput] = string(entry.WriterOutput)
+	}
+	if entry.Caller != nil {
+		m[j.jsonKeys.caller] = entry.Caller
+	}
+	if entry.Stack != "" {
+		m[j.jsonKeys.stack] = entry.Stack
+	}
 	return json.Marshal(m)
 }
 
+func (j *jsonMarshaller) getKeyValue(object interface{}) (string, interface{}, error) {
+	if typeName, format, data, ok := rawObject(object); ok {
+		if !j.options.FullyQualifiedTypeNames {
+			typeName = shortTypeName(typeName)
+		}
+		// raw json is re-emitted as is, anything else is encoded by encoding/json as a base64 string
+		if format == RawFormatJSON {
+			return typeName, json.RawMessage(data), nil
+		}
+		return typeName, data, nil
+	}
+	key, err := jsonReflectKey(reflect.TypeOf(object), j.options.FullyQualifiedTypeNames)
+	if err != nil {
+		return "", nil, err
+	}
+	return key, object, nil
+}
+
+func jsonReflectKey(reflectType reflect.Type, fullyQualified bool) (string, error) {
+	if fullyQualified {
+		return getReflectTypeName(reflectType)
+	}
+	return shortReflectKey(reflectType)
+}
+
 func shortReflectKey(reflectType reflect.Type) (string, error) {
 	for reflectType.Kind() == reflect.Ptr {
 		reflectType = reflectType.Elem()
@@ -369,7 +541,9 @@ func shortReflectKey(reflectType reflect.Type) (string, error) {
 	return name, nil
 }
 
-type protoMarshaller struct{}
+type protoMarshaller struct {
+	binary bool
+}
 
 func (p *protoMarshaller) Marshal(entry *Entry) ([]byte, error) {
 	protoEntry := &ProtoEntry{
@@ -378,32 +552,32 @@ func (p *protoMarshaller) Marshal(entry *Entry) ([]byte, error) {
 		Level:        entry.Level,
 		ContextTypeNameToContext: make(map[string][]byte),
 		WriterOutput:             entry.WriterOutput,
+		Stack:                    entry.Stack,
 	}
-	eventTypeName, err := getReflectTypeName(reflect.TypeOf(entry.Event))
-	if err != nil {
-		return nil, err
+	if entry.Caller != nil {
+		protoEntry.Caller = &ProtoCaller{entry.Caller.File, int64(entry.Caller.Line), entry.Caller.Function}
 	}
-	eventBytes, err := p.marshalBinary(entry.Event)
+	eventTypeName, eventBytes, err := p.marshalObject(entry.Event)
 	if err != nil {
 		return nil, err
 	}
 	protoEntry.EventTypeName = eventTypeName
 	protoEntry.Event = eventBytes
 	for _, context := range entry.Contexts {
-		contextTypeName, err := getReflectTypeName(reflect.TypeOf(context))
-		if err != nil {
-			return nil, err
-		}
-		contextBytes, err := p.marshalBinary(context)
+		contextTypeName, contextBytes, err := p.marshalObject(context)
 		if err != nil {
 			return nil, err
 		}
 		protoEntry.ContextTypeNameToContext[contextTypeName] = contextBytes
+		protoEntry.Contexts = append(protoEntry.Contexts, &ProtoContext{contextTypeName, contextBytes})
 	}
 	b, err := proto.Marshal(protoEntry)
 	if err != nil {
 		return nil, err
 	}
+	if p.binary {
+		return b, nil
+	}
 	buffer := bytes.NewBuffer(nil)
 	encoder := base64.NewEncoder(base64.StdEncoding, buffer)
 	if _, err := encoder.Write(b); err != nil {
@@ -416,6 +590,24 @@ func (p *protoMarshaller) Marshal(entry *Entry) ([]byte, error) {
 	return bufferBytes, nil
 }
 
+func (p *protoMarshaller) marshalObject(object interface{}) (string, []byte, error) {
+	if typeName, format, data, ok := rawObject(object); ok {
+		if format != RawFormatProto {
+			return "", nil, fmt.Errorf("ledge: cannot marshal raw %s of format %d with ProtoMarshaller", typeName, format)
+		}
+		return typeName, data, nil
+	}
+	typeName, err := getReflectTypeName(reflect.TypeOf(object))
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := p.marshalBinary(object)
+	if err != nil {
+		return "", nil, err
+	}
+	return typeName, data, nil
+}
+
 func (p *protoMarshaller) marshalBinary(object interface{}) ([]byte, error) {
 	if protoMessage, ok := object.(proto.Message); ok {
 		return proto.Marshal(protoMessage)
@@ -426,3 +618,24 @@ func (p *protoMarshaller) marshalBinary(object interface{}) ([]byte, error) {
 	}
 	return buffer.Bytes(), nil
 }
+
+// rawObject returns the type name, format and data of a *RawContext or *RawEvent.
+func rawObject(object interface{}) (string, RawFormat, []byte, bool) {
+	switch object := object.(type) {
+	case *RawContext:
+		return object.TypeName, object.Format, object.Data, true
+	case *RawEvent:
+		return object.TypeName, object.Format, object.Data, true
+	default:
+		return "", 0, nil, false
+	}
+}
+
+// shortTypeName returns the short name for a type name as returned by getReflectTypeName,
+// for example Foo for *"github.com/foo/bar".Foo. Short names are returned as is.
+func shortTypeName(typeName string) string {
+	if i := strings.LastIndex(typeName, "."); i >= 0 {
+		return typeName[i+1:]
+	}
+	return strings.TrimLeft(typeName, "*")
+}
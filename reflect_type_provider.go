@@ -11,10 +11,12 @@ const (
 )
 
 type reflectTypeProvider struct {
-	contextKeyToReflectType map[string]reflect.Type
-	eventKeyToReflectType   map[string]reflect.Type
-	contextReflectTypes     map[reflect.Type]bool
-	eventReflectTypes       map[reflect.Type]bool
+	contextKeyToReflectType      map[string]reflect.Type
+	eventKeyToReflectType        map[string]reflect.Type
+	contextShortKeyToReflectType map[string]reflect.Type
+	eventShortKeyToReflectType   map[string]reflect.Type
+	contextReflectTypes          map[reflect.Type]bool
+	eventReflectTypes            map[reflect.Type]bool
 }
 
 func newReflectTypeProvider(
@@ -22,28 +24,32 @@ func newReflectTypeProvider(
 ) (*reflectTypeProvider, error) {
 	contextKeyToReflectType := make(map[string]reflect.Type)
 	eventKeyToReflectType := make(map[string]reflect.Type)
+	contextShortKeyToReflectType := make(map[string]reflect.Type)
+	eventShortKeyToReflectType := make(map[string]reflect.Type)
 	contextReflectTypes := make(map[reflect.Type]bool)
 	eventReflectTypes := make(map[reflect.Type]bool)
 	if specification != nil {
 		for _, t := range specification.ContextTypes {
-			if err := addToKeyToReflectType(contextKeyToReflectType, contextReflectTypes, t); err != nil {
+			if err := addToKeyToReflectType(contextKeyToReflectType, contextShortKeyToReflectType, contextReflectTypes, t); err != nil {
 				return nil, err
 			}
 		}
 		for _, t := range specification.EventTypes {
-			if err := addToKeyToReflectType(eventKeyToReflectType, eventReflectTypes, t); err != nil {
+			if err := addToKeyToReflectType(eventKeyToReflectType, eventShortKeyToReflectType, eventReflectTypes, t); err != nil {
 				return nil, err
 			}
 		}
 	}
 	for _, t := range DefaultEventTypes {
-		if err := addToKeyToReflectType(eventKeyToReflectType, eventReflectTypes, t); err != nil {
+		if err := addToKeyToReflectType(eventKeyToReflectType, eventShortKeyToReflectType, eventReflectTypes, t); err != nil {
 			return nil, err
 		}
 	}
 	return &reflectTypeProvider{
 		contextKeyToReflectType,
 		eventKeyToReflectType,
+		contextShortKeyToReflectType,
+		eventShortKeyToReflectType,
 		contextReflectTypes,
 		eventReflectTypes,
 	}, nil
@@ -57,6 +63,14 @@ func (r *reflectTypeProvider) getEventReflectType(key string) (reflect.Type, err
 	return r.getReflectType(r.eventKeyToReflectType, key)
 }
 
+func (r *reflectTypeProvider) getContextReflectTypeForShortKey(shortKey string) (reflect.Type, error) {
+	return r.getReflectTypeForShortKey(r.contextShortKeyToReflectType, shortKey)
+}
+
+func (r *reflectTypeProvider) getEventReflectTypeForShortKey(shortKey string) (reflect.Type, error) {
+	return r.getReflectTypeForShortKey(r.eventShortKeyToReflectType, shortKey)
+}
+
 func (r *reflectTypeProvider) validateContextReflectType(reflectType reflect.Type) error {
 	return r.validateReflectType(r.contextReflectTypes, reflectType)
 }
@@ -66,9 +80,21 @@ func (r *reflectTypeProvider) validateEventReflectType(reflectType reflect.Type)
 }
 
 func (r *reflectTypeProvider) getReflectType(m map[string]reflect.Type, key string) (reflect.Type, error) {
-	reflectType, ok := m[key]
+	reflectType, ok := m[trimVendoring(key)]
+	if !ok {
+		return nil, &noReflectTypeError{key}
+	}
+	return reflectType, nil
+}
+
+func (r *reflectTypeProvider) getReflectTypeForShortKey(m map[string]reflect.Type, shortKey string) (reflect.Type, error) {
+	reflectType, ok := m[shortKey]
 	if !ok {
-		return nil, fmt.Errorf("ledge: no reflect type for %s", key)
+		return nil, &noReflectTypeError{shortKey}
+	}
+	// a nil value means two types in different packages share this short name
+	if reflectType == nil {
+		return nil, fmt.Errorf("ledge: short type name %s is ambiguous, use fully qualified type names", shortKey)
 	}
 	return reflectType, nil
 }
@@ -80,14 +106,28 @@ func (r *reflectTypeProvider) validateReflectType(m map[reflect.Type]bool, refle
 	return nil
 }
 
-func addToKeyToReflectType(keyToReflectType map[string]reflect.Type, reflectTypes map[reflect.Type]bool, t interface{}) error {
+func addToKeyToReflectType(
+	keyToReflectType map[string]reflect.Type,
+	shortKeyToReflectType map[string]reflect.Type,
+	reflectTypes map[reflect.Type]bool,
+	t interface{},
+) error {
 	reflectType := reflect.TypeOf(t)
 	key, err := getReflectTypeName(reflectType)
 	key = trimVendoring(key)
 	if err != nil {
 		return err
 	}
+	shortKey, err := shortReflectKey(reflectType)
+	if err != nil {
+		return err
+	}
 	keyToReflectType[key] = reflectType
+	if existing, ok := shortKeyToReflectType[shortKey]; ok && existing != reflectType {
+		shortKeyToReflectType[shortKey] = nil
+	} else {
+		shortKeyToReflectType[shortKey] = reflectType
+	}
 	reflectTypes[reflectType] = true
 	return nil
 }
@@ -103,3 +143,13 @@ func trimVendoring(key string) string {
 	}
 	return key
 }
+
+// noReflectTypeError is returned when a type name is not part of the Specification,
+// so that lenient Unmarshallers can tell it apart from other errors.
+type noReflectTypeError struct {
+	key string
+}
+
+func (n *noReflectTypeError) Error() string {
+	return fmt.Sprintf("ledge: no reflect type for %s", n.key)
+}
@@ -0,0 +1,422 @@
+package ledge
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	rotatedTimeFormat = "20060102T150405.000000000"
+	gzipSuffix        = ".gz"
+)
+
+type rotatingFileWriter struct {
+	path     string
+	options  RotatingFileWriterOptions
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	closed   bool
+	lock     *sync.Mutex
+}
+
+func newRotatingFileWriter(
+	path string,
+	options RotatingFileWriterOptions,
+) (*rotatingFileWriter, error) {
+	rotatingFileWriter := &rotatingFileWriter{
+		path,
+		options,
+		nil,
+		0,
+		time.Time{},
+		false,
+		&sync.Mutex{},
+	}
+	if err := rotatingFileWriter.open(); err != nil {
+		return nil, err
+	}
+	return rotatingFileWriter, nil
+}
+
+func (r *rotatingFileWriter) Write(p []byte) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.closed {
+		return 0, fmt.Errorf("ledge: write to closed file %s", r.path)
+	}
+	// the file is nil if reopening after a failed rotation failed as well
+	if r.file == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	if r.shouldRotate(int64(len(p))) {
+		// the file is reopened if rotation fails, so p is still written and the error is
+		// only reported, rather than losing every Entry until rotation succeeds again
+		if err := r.rotate(); err != nil {
+			if r.file == nil {
+				return 0, err
+			}
+			r.writeError(err)
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFileWriter) Rotate() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.closed {
+		return fmt.Errorf("ledge: rotate of closed file %s", r.path)
+	}
+	if r.file == nil {
+		if err := r.open(); err != nil {
+			return err
+		}
+	}
+	return r.rotate()
+}
+
+func (r *rotatingFileWriter) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func (r *rotatingFileWriter) shouldRotate(writeSize int64) bool {
+	// a single Write larger than MaxSize still goes to its own file whole
+	if r.options.MaxSize > 0 && r.size > 0 && r.size+writeSize > r.options.MaxSize {
+		return true
+	}
+	if r.options.Interval > 0 && !r.now().Before(r.openedAt.Add(r.options.Interval)) {
+		return true
+	}
+	return false
+}
+
+func (r *rotatingFileWriter) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	fileInfo, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	r.file = file
+	r.size = fileInfo.Size()
+	r.openedAt = r.now()
+	return nil
+}
+
+func (r *rotatingFileWriter) rotate() (retErr error) {
+	if r.size == 0 {
+		r.openedAt = r.now()
+		return nil
+	}
+	// reopen the file if rotation fails at any point, so that one failed rotation does
+	// not stop logging
+	defer func() {
+		if r.file == nil {
+			if err := r.open(); err != nil && retErr == nil {
+				retErr = err
+			}
+		}
+	}()
+	err := r.file.Close()
+	r.file = nil
+	if err != nil {
+		return err
+	}
+	rotatedPath, err := r.nextRotatedPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(r.path, rotatedPath); err != nil {
+		return err
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+	if r.options.Compress {
+		if err := gzipFile(rotatedPath); err != nil {
+			return err
+		}
+	}
+	return r.prune()
+}
+
+// nextRotatedPath returns the path to rotate to. If files have already been rotated
+// at the same time, an index greater than any existing one is appended so that
+// files still sort in the order they were rotated.
+func (r *rotatingFileWriter) nextRotatedPath() (string, error) {
+	now := r.now().UTC()
+	rotatedFiles, err := getRotatedFiles(r.path)
+	if err != nil {
+		return "", err
+	}
+	index := -1
+	for _, rotatedFile := range rotatedFiles {
+		if rotatedFile.time.Equal(now) && rotatedFile.index > index {
+			index = rotatedFile.index
+		}
+	}
+	rotatedPath := fmt.Sprintf("%s.%s", r.path, now.Format(rotatedTimeFormat))
+	if index >= 0 {
+		rotatedPath = fmt.Sprintf("%s.%d", rotatedPath, index+1)
+	}
+	return rotatedPath, nil
+}
+
+func (r *rotatingFileWriter) prune() error {
+	rotatedFiles, err := getRotatedFiles(r.path)
+	if err != nil {
+		return err
+	}
+	if r.options.MaxFiles > 0 && len(rotatedFiles) > r.options.MaxFiles {
+		for _, rotatedFile := range rotatedFiles[:len(rotatedFiles)-r.options.MaxFiles] {
+			if err := os.Remove(rotatedFile.path); err != nil {
+				return err
+			}
+		}
+		rotatedFiles = rotatedFiles[len(rotatedFiles)-r.options.MaxFiles:]
+	}
+	if r.options.MaxAge > 0 {
+		cutoff := r.now().Add(-r.options.MaxAge)
+		for _, rotatedFile := range rotatedFiles {
+			if rotatedFile.time.Before(cutoff) {
+				if err := os.Remove(rotatedFile.path); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (r *rotatingFileWriter) writeError(err error) {
+	backupWriter := r.options.BackupWriter
+	if backupWriter == nil {
+		backupWriter = os.Stderr
+	}
+	_, _ = backupWriter.Write([]byte(fmt.Sprintf("ledge: failed to rotate %s: %s", r.path, err.Error())))
+}
+
+func (r *rotatingFileWriter) now() time.Time {
+	if r.options.Timer != nil {
+		return r.options.Timer.Now()
+	}
+	return systemTimerInstance.Now()
+}
+
+type rotatedFile struct {
+	path  string
+	time  time.Time
+	index int
+}
+
+// getRotatedFiles returns the rotated files for path, oldest first.
+func getRotatedFiles(path string) ([]*rotatedFile, error) {
+	fileInfos, err := ioutil.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	prefix := filepath.Base(path) + "."
+	var rotatedFiles []*rotatedFile
+	for _, fileInfo := range fileInfos {
+		name := fileInfo.Name()
+		if fileInfo.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		suffix := strings.TrimSuffix(strings.TrimPrefix(name, prefix), gzipSuffix)
+		if len(suffix) < len(rotatedTimeFormat) {
+			continue
+		}
+		rotatedTime, err := time.Parse(rotatedTimeFormat, suffix[:len(rotatedTimeFormat)])
+		if err != nil {
+			continue
+		}
+		index := 0
+		if rest := suffix[len(rotatedTimeFormat):]; rest != "" {
+			if !strings.HasPrefix(rest, ".") {
+				continue
+			}
+			if index, err = strconv.Atoi(rest[1:]); err != nil {
+				continue
+			}
+		}
+		rotatedFiles = append(rotatedFiles, &rotatedFile{filepath.Join(filepath.Dir(path), name), rotatedTime, index})
+	}
+	sort.Sort(rotatedFilesByTime(rotatedFiles))
+	return rotatedFiles, nil
+}
+
+type rotatedFilesByTime []*rotatedFile
+
+func (r rotatedFilesByTime) Len() int      { return len(r) }
+func (r rotatedFilesByTime) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r rotatedFilesByTime) Less(i, j int) bool {
+	if r[i].time.Equal(r[j].time) {
+		return r[i].index < r[j].index
+	}
+	return r[i].time.Before(r[j].time)
+}
+
+func getRotatedFilePaths(path string) ([]string, error) {
+	rotatedFiles, err := getRotatedFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, rotatedFile := range rotatedFiles {
+		paths = append(paths, rotatedFile.path)
+	}
+	exists, err := fileExists(path)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+type multiFileReader struct {
+	paths  []string
+	file   *os.File
+	reader io.Reader
+}
+
+func newMultiFileReader(
+	paths []string,
+) *multiFileReader {
+	return &multiFileReader{
+		paths,
+		nil,
+		nil,
+	}
+}
+
+func (m *multiFileReader) Read(p []byte) (int, error) {
+	for {
+		if m.reader == nil {
+			if len(m.paths) == 0 {
+				return 0, io.EOF
+			}
+			if err := m.next(); err != nil {
+				return 0, err
+			}
+		}
+		n, err := m.reader.Read(p)
+		if err == io.EOF {
+			if closeErr := m.closeFile(); closeErr != nil {
+				return n, closeErr
+			}
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (m *multiFileReader) Close() error {
+	m.paths = nil
+	return m.closeFile()
+}
+
+func (m *multiFileReader) next() error {
+	path := m.paths[0]
+	m.paths = m.paths[1:]
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	m.file = file
+	m.reader = file
+	if strings.HasSuffix(path, gzipSuffix) {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			_ = m.closeFile()
+			return err
+		}
+		m.reader = gzipReader
+	}
+	return nil
+}
+
+func (m *multiFileReader) closeFile() error {
+	m.reader = nil
+	if m.file == nil {
+		return nil
+	}
+	err := m.file.Close()
+	m.file = nil
+	return err
+}
+
+func gzipFile(path string) (retErr error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := file.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	gzipPath := path + gzipSuffix
+	gzipFile, err := os.OpenFile(gzipPath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	gzipWriter := gzip.NewWriter(gzipFile)
+	if _, err := io.Copy(gzipWriter, file); err != nil {
+		_ = gzipFile.Close()
+		_ = os.Remove(gzipPath)
+		return err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		_ = gzipFile.Close()
+		_ = os.Remove(gzipPath)
+		return err
+	}
+	if err := gzipFile.Close(); err != nil {
+		_ = os.Remove(gzipPath)
+		return err
+	}
+	return os.Remove(path)
+}
+
+func fileExists(path string) (bool, error) {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,379 @@
+package ledge
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	minRateLimitSweepSize = 1024
+)
+
+type samplingKey struct {
+	level         Level
+	eventTypeName string
+	contexts      string
+}
+
+// suppressedCounter is not safe for concurrent use, the SamplingFilter holding it locks.
+type suppressedCounter struct {
+	keyToCount map[samplingKey]uint64
+}
+
+func newSuppressedCounter() *suppressedCounter {
+	return &suppressedCounter{
+		make(map[samplingKey]uint64),
+	}
+}
+
+func (s *suppressedCounter) add(level Level, eventTypeName string) {
+	s.keyToCount[samplingKey{level, eventTypeName, ""}]++
+}
+
+func (s *suppressedCounter) getAndReset() []*SuppressedCount {
+	if len(s.keyToCount) == 0 {
+		return nil
+	}
+	suppressedCounts := make([]*SuppressedCount, 0, len(s.keyToCount))
+	for key, count := range s.keyToCount {
+		suppressedCounts = append(suppressedCounts, &SuppressedCount{key.level, key.eventTypeName, count})
+	}
+	sortSuppressedCounts(suppressedCounts)
+	s.keyToCount = make(map[samplingKey]uint64)
+	return suppressedCounts
+}
+
+type everyNFilter struct {
+	n                    uint64
+	lock                 *sync.Mutex
+	eventTypeNameToCount map[string]uint64
+	suppressedCounter    *suppressedCounter
+}
+
+func newEveryNFilter(
+	n uint64,
+) *everyNFilter {
+	return &everyNFilter{
+		n,
+		&sync.Mutex{},
+		make(map[string]uint64),
+		newSuppressedCounter(),
+	}
+}
+
+func (e *everyNFilter) Include(entry *Entry) bool {
+	if isSuppressedEntry(entry) {
+		return true
+	}
+	eventTypeName := getEntryEventTypeName(entry)
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	count := e.eventTypeNameToCount[eventTypeName]
+	e.eventTypeNameToCount[eventTypeName] = count + 1
+	if e.n <= 1 || count%e.n == 0 {
+		return true
+	}
+	e.suppressedCounter.add(entry.Level, eventTypeName)
+	return false
+}
+
+func (e *everyNFilter) Suppressed() []*SuppressedCount {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.suppressedCounter.getAndReset()
+}
+
+type firstNFilter struct {
+	n                 uint64
+	thereafter        uint64
+	interval          time.Duration
+	timer             Timer
+	lock              *sync.Mutex
+	intervalStart     time.Time
+	keyToCount        map[samplingKey]uint64
+	suppressedCounter *suppressedCounter
+}
+
+func newFirstNFilter(
+	n uint64,
+	thereafter uint64,
+	interval time.Duration,
+	options SamplingFilterOptions,
+) (*firstNFilter, error) {
+	if interval < 0 {
+		return nil, fmt.Errorf("ledge: negative interval %v", interval)
+	}
+	timer := options.Timer
+	if timer == nil {
+		timer = systemTimerInstance
+	}
+	return &firstNFilter{
+		n,
+		thereafter,
+		interval,
+		timer,
+		&sync.Mutex{},
+		timer.Now(),
+		make(map[samplingKey]uint64),
+		newSuppressedCounter(),
+	}, nil
+}
+
+func (f *firstNFilter) Include(entry *Entry) bool {
+	if isSuppressedEntry(entry) {
+		return true
+	}
+	eventTypeName := getEntryEventTypeName(entry)
+	key := samplingKey{entry.Level, eventTypeName, ""}
+	now := f.timer.Now()
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	// an interval of 0 never resets the counts
+	if f.interval > 0 && !now.Before(f.intervalStart.Add(f.interval)) {
+		f.intervalStart = now
+		f.keyToCount = make(map[samplingKey]uint64)
+	}
+	count := f.keyToCount[key] + 1
+	f.keyToCount[key] = count
+	if count <= f.n || (f.thereafter > 0 && (count-f.n)%f.thereafter == 0) {
+		return true
+	}
+	f.suppressedCounter.add(entry.Level, eventTypeName)
+	return false
+}
+
+func (f *firstNFilter) Suppressed() []*SuppressedCount {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.suppressedCounter.getAndReset()
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type rateLimitFilter struct {
+	rate              float64
+	burst             float64
+	timer             Timer
+	lock              *sync.Mutex
+	keyToTokenBucket  map[samplingKey]*tokenBucket
+	nextSweepSize     int
+	suppressedCounter *suppressedCounter
+}
+
+func newRateLimitFilter(
+	rate float64,
+	burst int,
+	options SamplingFilterOptions,
+) *rateLimitFilter {
+	timer := options.Timer
+	if timer == nil {
+		timer = systemTimerInstance
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimitFilter{
+		rate,
+		float64(burst),
+		timer,
+		&sync.Mutex{},
+		make(map[samplingKey]*tokenBucket),
+		minRateLimitSweepSize,
+		newSuppressedCounter(),
+	}
+}
+
+func (r *rateLimitFilter) Include(entry *Entry) bool {
+	if isSuppressedEntry(entry) {
+		return true
+	}
+	eventTypeName := getEntryEventTypeName(entry)
+	key := samplingKey{entry.Level, eventTypeName, getEntryContextsKey(entry)}
+	now := r.timer.Now()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	bucket, ok := r.keyToTokenBucket[key]
+	if !ok {
+		if len(r.keyToTokenBucket) >= r.nextSweepSize {
+			r.sweep(now)
+		}
+		bucket = &tokenBucket{r.burst, now}
+		r.keyToTokenBucket[key] = bucket
+	}
+	r.refill(bucket, now)
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true
+	}
+	r.suppressedCounter.add(entry.Level, eventTypeName)
+	return false
+}
+
+func (r *rateLimitFilter) Suppressed() []*SuppressedCount {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.suppressedCounter.getAndReset()
+}
+
+func (r *rateLimitFilter) refill(bucket *tokenBucket, now time.Time) {
+	if elapsed := now.Sub(bucket.last); elapsed > 0 {
+		bucket.tokens += elapsed.Seconds() * r.rate
+		if bucket.tokens > r.burst {
+			bucket.tokens = r.burst
+		}
+		bucket.last = now
+	}
+}
+
+// sweep removes full buckets, which behave the same as missing buckets, so
+// that keys with Context values such as request IDs do not grow the map forever.
+func (r *rateLimitFilter) sweep(now time.Time) {
+	for key, bucket := range r.keyToTokenBucket {
+		r.refill(bucket, now)
+		if bucket.tokens >= r.burst {
+			delete(r.keyToTokenBucket, key)
+		}
+	}
+	r.nextSweepSize = 2 * len(r.keyToTokenBucket)
+	if r.nextSweepSize < minRateLimitSweepSize {
+		r.nextSweepSize = minRateLimitSweepSize
+	}
+}
+
+type suppressedReporter struct {
+	logger   Logger
+	interval time.Duration
+	filters  []SamplingFilter
+	stop     chan struct{}
+	done     chan struct{}
+	once     *sync.Once
+}
+
+func newSuppressedReporter(
+	logger Logger,
+	interval time.Duration,
+	filters []SamplingFilter,
+) *suppressedReporter {
+	if interval <= 0 {
+		interval = DefaultSuppressedReportInterval
+	}
+	suppressedReporter := &suppressedReporter{
+		logger,
+		interval,
+		filters,
+		make(chan struct{}),
+		make(chan struct{}),
+		&sync.Once{},
+	}
+	go suppressedReporter.run()
+	return suppressedReporter
+}
+
+func (s *suppressedReporter) Stop() {
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+}
+
+func (s *suppressedReporter) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.report()
+		case <-s.stop:
+			s.report()
+			return
+		}
+	}
+}
+
+func (s *suppressedReporter) report() {
+	keyToCount := make(map[samplingKey]uint64)
+	for _, filter := range s.filters {
+		for _, suppressedCount := range filter.Suppressed() {
+			keyToCount[samplingKey{suppressedCount.Level, suppressedCount.EventTypeName, ""}] += suppressedCount.Count
+		}
+	}
+	if len(keyToCount) == 0 {
+		return
+	}
+	suppressedEvent := &SuppressedEvent{}
+	for key, count := range keyToCount {
+		suppressedEvent.Counts = append(suppressedEvent.Counts, &SuppressedCount{key.level, key.eventTypeName, count})
+	}
+	sortSuppressedCounts(suppressedEvent.Counts)
+	s.logger.Warn(suppressedEvent)
+}
+
+func sortSuppressedCounts(suppressedCounts []*SuppressedCount) {
+	sort.Slice(suppressedCounts, func(i int, j int) bool {
+		if suppressedCounts[i].Level != suppressedCounts[j].Level {
+			return suppressedCounts[i].Level < suppressedCounts[j].Level
+		}
+		return suppressedCounts[i].EventTypeName < suppressedCounts[j].EventTypeName
+	})
+}
+
+// the SuppressedEvent reports themselves are never sampled
+func isSuppressedEntry(entry *Entry) bool {
+	_, ok := entry.Event.(*SuppressedEvent)
+	return ok
+}
+
+func getEntryEventTypeName(entry *Entry) string {
+	if rawEvent, ok := entry.Event.(*RawEvent); ok {
+		return rawEvent.TypeName
+	}
+	reflectType := reflect.TypeOf(entry.Event)
+	eventTypeName, err := getReflectTypeName(reflectType)
+	if err != nil {
+		return fmt.Sprintf("%v", reflectType)
+	}
+	return eventTypeName
+}
+
+// getEntryContextsKey returns a key built from the type name and value of every Context, so
+// that equal Contexts have the same key even if they are pointers.
+func getEntryContextsKey(entry *Entry) string {
+	if len(entry.Contexts) == 0 {
+		return ""
+	}
+	buffer := bytes.NewBuffer(nil)
+	for _, context := range entry.Contexts {
+		buffer.WriteString(reflect.TypeOf(context).String())
+		buffer.WriteByte('=')
+		value := reflect.ValueOf(context)
+		for value.Kind() == reflect.Ptr && !value.IsNil() {
+			value = value.Elem()
+		}
+		switch value.Kind() {
+		case reflect.String:
+			buffer.WriteString(strconv.Quote(value.String()))
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			buffer.WriteString(strconv.FormatInt(value.Int(), 10))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			buffer.WriteString(strconv.FormatUint(value.Uint(), 10))
+		case reflect.Bool:
+			buffer.WriteString(strconv.FormatBool(value.Bool()))
+		default:
+			data, err := json.Marshal(context)
+			if err != nil {
+				data = []byte(fmt.Sprintf("%+v", value))
+			}
+			buffer.Write(data)
+		}
+		buffer.WriteByte(';')
+	}
+	return buffer.String()
+}
@@ -0,0 +1,110 @@
+package ledge
+
+import (
+	"bytes"
+	"log"
+	"strings"
+)
+
+const (
+	stdLoggerPrefixField = "prefix"
+	stdLoggerFileField   = "file"
+	stdLoggerDateLen     = len("2006/01/02 ")
+	stdLoggerTimeLen     = len("15:04:05 ")
+	stdLoggerMicrosLen   = len(".000000")
+)
+
+// stdLoggerWriter is the io.Writer of a *log.Logger, which calls Write once per message.
+type stdLoggerWriter struct {
+	unstructuredLogger UnstructuredLogger
+	level              Level
+	logger             *log.Logger
+}
+
+func newStdLogger(
+	unstructuredLogger UnstructuredLogger,
+	level Level,
+	prefix string,
+	flags int,
+) *log.Logger {
+	stdLoggerWriter := &stdLoggerWriter{
+		unstructuredLogger,
+		level,
+		nil,
+	}
+	stdLoggerWriter.logger = log.New(stdLoggerWriter, prefix, flags)
+	return stdLoggerWriter.logger
+}
+
+func redirectStdLog(
+	unstructuredLogger UnstructuredLogger,
+	level Level,
+) func() {
+	logger := log.Default()
+	writer := logger.Writer()
+	logger.SetOutput(
+		&stdLoggerWriter{
+			unstructuredLogger,
+			level,
+			logger,
+		},
+	)
+	return func() { logger.SetOutput(writer) }
+}
+
+func (s *stdLoggerWriter) Write(p []byte) (int, error) {
+	msg, fields := parseStdLoggerOutput(string(bytes.TrimSuffix(p, []byte("\n"))), s.logger.Prefix(), s.logger.Flags())
+	logUnstructured(s.unstructuredLogger.WithFields(fields), s.level, msg)
+	return len(p), nil
+}
+
+// parseStdLoggerOutput splits the header written by a *log.Logger from the message.
+// The date and time are dropped as every Entry has a Time.
+func parseStdLoggerOutput(output string, prefix string, flags int) (string, Fields) {
+	fields := Fields{}
+	if prefix != "" {
+		fields[stdLoggerPrefixField] = prefix
+	}
+	if flags&log.Lmsgprefix == 0 {
+		output = strings.TrimPrefix(output, prefix)
+	}
+	if flags&log.Ldate != 0 && len(output) >= stdLoggerDateLen {
+		output = output[stdLoggerDateLen:]
+	}
+	if flags&(log.Ltime|log.Lmicroseconds) != 0 {
+		timeLen := stdLoggerTimeLen
+		if flags&log.Lmicroseconds != 0 {
+			timeLen += stdLoggerMicrosLen
+		}
+		if len(output) >= timeLen {
+			output = output[timeLen:]
+		}
+	}
+	if flags&(log.Lshortfile|log.Llongfile) != 0 {
+		if i := strings.Index(output, ": "); i >= 0 {
+			fields[stdLoggerFileField] = output[:i]
+			output = output[i+2:]
+		}
+	}
+	if flags&log.Lmsgprefix != 0 {
+		output = strings.TrimPrefix(output, prefix)
+	}
+	return output, fields
+}
+
+func logUnstructured(unstructuredLogger UnstructuredLogger, level Level, msg string) {
+	switch level {
+	case Level_DEBUG:
+		unstructuredLogger.Debug(msg)
+	case Level_WARN:
+		unstructuredLogger.Warn(msg)
+	case Level_ERROR:
+		unstructuredLogger.Error(msg)
+	case Level_FATAL:
+		unstructuredLogger.Fatal(msg)
+	case Level_PANIC:
+		unstructuredLogger.Panic(msg)
+	default:
+		unstructuredLogger.Info(msg)
+	}
+}
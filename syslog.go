@@ -0,0 +1,323 @@
+package ledge
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	syslogVersion        = 1
+	syslogTimeFormat     = "2006-01-02T15:04:05.000000Z07:00"
+	syslogNilValue       = "-"
+	syslogMaxHostnameLen = 255
+	syslogMaxAppNameLen  = 48
+	syslogMaxProcIDLen   = 128
+	syslogMaxMsgIDLen    = 32
+	syslogMaxSDNameLen   = 32
+	defaultSyslogSDID    = "ledge@32473"
+)
+
+var (
+	syslogSDValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+)
+
+type syslogMarshaller struct {
+	facility SyslogFacility
+	hostname string
+	appName  string
+	procID   string
+	sdID     string
+}
+
+func newSyslogMarshaller(
+	options SyslogMarshallerOptions,
+) *syslogMarshaller {
+	facility := options.Facility
+	if facility == SyslogFacilityKern {
+		facility = SyslogFacilityUser
+	}
+	hostname := options.Hostname
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+	appName := options.AppName
+	if appName == "" && len(os.Args) > 0 {
+		appName = filepath.Base(os.Args[0])
+	}
+	procID := options.ProcID
+	if procID == "" {
+		procID = strconv.Itoa(os.Getpid())
+	}
+	sdID := options.SDID
+	if sdID == "" {
+		sdID = defaultSyslogSDID
+	}
+	return &syslogMarshaller{
+		facility,
+		getSyslogHeaderValue(hostname, syslogMaxHostnameLen),
+		getSyslogHeaderValue(appName, syslogMaxAppNameLen),
+		getSyslogHeaderValue(procID, syslogMaxProcIDLen),
+		getSyslogSDName(sdID),
+	}
+}
+
+func (s *syslogMarshaller) Marshal(entry *Entry) ([]byte, error) {
+	eventKey, err := textMarshallerObjectKeyString(entry.Event)
+	if err != nil {
+		return nil, err
+	}
+	severity, ok := levelToSyslogSeverity[entry.Level]
+	if !ok {
+		severity = levelToSyslogSeverity[Level_INFO]
+	}
+	timestamp := syslogNilValue
+	if !entry.Time.IsZero() {
+		timestamp = entry.Time.UTC().Format(syslogTimeFormat)
+	}
+	buffer := bytes.NewBuffer(nil)
+	fmt.Fprintf(
+		buffer,
+		"<%d>%d %s %s %s %s %s ",
+		int(s.facility)*8+severity,
+		syslogVersion,
+		timestamp,
+		s.hostname,
+		s.appName,
+		s.procID,
+		getSyslogHeaderValue(eventKey, syslogMaxMsgIDLen),
+	)
+	if err := s.writeStructuredData(buffer, entry.Contexts); err != nil {
+		return nil, err
+	}
+	var message string
+	if len(entry.WriterOutput) > 0 {
+		message = trimRightSpace(string(entry.WriterOutput))
+	} else if unstructuredEvent, ok := entry.Event.(*UnstructuredEvent); ok {
+		message = unstructuredEvent.Msg
+		for _, key := range sortedFieldKeys(unstructuredEvent.Fields) {
+			message = fmt.Sprintf("%s %s=%s", message, key, fieldValueString(unstructuredEvent.Fields[key]))
+		}
+		message = strings.TrimLeft(message, " ")
+	} else {
+		message = textMarshallerObjectValueString(entry.Event)
+	}
+	if stack := getEntryStack(entry); stack != "" {
+		message = strings.TrimLeft(message+"\n"+stack, "\n")
+	}
+	if message != "" {
+		buffer.WriteString(" ")
+		buffer.WriteString(message)
+	}
+	return buffer.Bytes(), nil
+}
+
+// writeStructuredData writes a single SD-ELEMENT with the flattened Contexts as SD-PARAMs,
+// in the order of the Contexts and sorted by name within each Context, or - if there are none.
+func (s *syslogMarshaller) writeStructuredData(buffer *bytes.Buffer, contexts []Context) error {
+	var params []string
+	sdNames := make(map[string]string)
+	for _, context := range contexts {
+		contextKey, err := textMarshallerObjectKeyString(context)
+		if err != nil {
+			return err
+		}
+		contextValues := make(map[string]interface{})
+		if err := flattenJSONObject(contextKey, context, ".", func(key string, value interface{}) {
+			contextValues[key] = value
+		}); err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(contextValues))
+		for key := range contextValues {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			params = append(
+				params,
+				fmt.Sprintf(`%s="%s"`, getUniqueSyslogSDName(sdNames, key), syslogSDValueEscaper.Replace(getSyslogSDValue(contextValues[key]))),
+			)
+		}
+	}
+	if len(params) == 0 {
+		buffer.WriteString(syslogNilValue)
+		return nil
+	}
+	fmt.Fprintf(buffer, "[%s %s]", s.sdID, strings.Join(params, " "))
+	return nil
+}
+
+// getSyslogHeaderValue returns s truncated to maxLen with characters outside of PRINTUSASCII
+// replaced with _, or - if s is empty.
+func getSyslogHeaderValue(s string, maxLen int) string {
+	if s == "" {
+		return syslogNilValue
+	}
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > maxLen {
+		return s[:maxLen]
+	}
+	return s
+}
+
+// getSyslogSDName returns s as a valid SD-NAME, which is a header value that can not contain
+// =, ] or ", and is at most 32 characters.
+func getSyslogSDName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, s)
+	return getSyslogHeaderValue(s, syslogMaxSDNameLen)
+}
+
+// getUniqueSyslogSDName returns the SD-NAME for key, with a ~N suffix if another key already
+// has the same SD-NAME once truncated or with characters replaced. sdNames maps the SD-NAMEs
+// returned so far to their keys.
+func getUniqueSyslogSDName(sdNames map[string]string, key string) string {
+	baseName := getSyslogSDName(key)
+	sdName := baseName
+	for i := 2; ; i++ {
+		if sdNameKey, ok := sdNames[sdName]; !ok || sdNameKey == key {
+			sdNames[sdName] = key
+			return sdName
+		}
+		suffix := "~" + strconv.Itoa(i)
+		if len(baseName)+len(suffix) > syslogMaxSDNameLen {
+			sdName = baseName[:syslogMaxSDNameLen-len(suffix)] + suffix
+		} else {
+			sdName = baseName + suffix
+		}
+	}
+}
+
+func getSyslogSDValue(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	case bool:
+		return strconv.FormatBool(value)
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(data)
+	}
+}
+
+type syslogWriter struct {
+	network string
+	address string
+	stream  bool
+	conn    net.Conn
+	closed  bool
+	lock    *sync.Mutex
+}
+
+func newSyslogWriter(
+	network string,
+	address string,
+) (*syslogWriter, error) {
+	var stream bool
+	switch network {
+	case "unix", "tcp", "tcp4", "tcp6":
+		stream = true
+	case "unixgram", "udp", "udp4", "udp6":
+	default:
+		return nil, fmt.Errorf("ledge: unsupported syslog network %s", network)
+	}
+	syslogWriter := &syslogWriter{
+		network,
+		address,
+		stream,
+		nil,
+		false,
+		&sync.Mutex{},
+	}
+	if err := syslogWriter.dial(); err != nil {
+		return nil, err
+	}
+	return syslogWriter, nil
+}
+
+func (s *syslogWriter) Write(p []byte) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return 0, fmt.Errorf("ledge: write to closed syslog writer for %s %s", s.network, s.address)
+	}
+	message := bytes.TrimSuffix(p, []byte("\n"))
+	if s.stream {
+		message = append([]byte(fmt.Sprintf("%d ", len(message))), message...)
+	}
+	if s.conn != nil {
+		n, err := s.writeConn(message)
+		if err == nil {
+			return len(p), nil
+		}
+		// resending part of a frame would corrupt the stream, so only reconnect on the next write
+		if s.stream && n > 0 {
+			return 0, err
+		}
+	}
+	// the syslog daemon may have been restarted, so reconnect once
+	if err := s.dial(); err != nil {
+		return 0, err
+	}
+	if _, err := s.writeConn(message); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// writeConn writes message to the connection, dropping the connection on error so that
+// the next Write reconnects rather than continuing after a partial frame.
+func (s *syslogWriter) writeConn(message []byte) (int, error) {
+	n, err := s.conn.Write(message)
+	if err != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+	return n, err
+}
+
+func (s *syslogWriter) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+func (s *syslogWriter) dial() error {
+	conn, err := net.Dial(s.network, s.address)
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+	return nil
+}
@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/gob"
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/golang/protobuf/proto"
@@ -13,10 +16,12 @@ import (
 
 type protoUnmarshaller struct {
 	reflectTypeProvider *reflectTypeProvider
+	options             UnmarshallerOptions
 }
 
 func newProtoUnmarshaller(
 	specification *Specification,
+	options UnmarshallerOptions,
 ) (*protoUnmarshaller, error) {
 	reflectTypeProvider, err := newReflectTypeProvider(specification)
 	if err != nil {
@@ -24,19 +29,14 @@ func newProtoUnmarshaller(
 	}
 	return &protoUnmarshaller{
 		reflectTypeProvider,
+		options,
 	}, nil
 }
 
 func (p *protoUnmarshaller) Unmarshal(buffer []byte) (*Entry, error) {
-	decoder := base64.NewDecoder(base64.StdEncoding, bytes.NewBuffer(buffer))
-	bBuffer := bytes.NewBuffer(nil)
-	if _, err := bBuffer.ReadFrom(decoder); err != nil {
-		return nil, fmt.Errorf("Failed to decode buffer: %s - %s", err.Error(), string(buffer))
-	}
-	b := bBuffer.Bytes()
-	protoEntry := &ProtoEntry{}
-	if err := proto.Unmarshal(b, protoEntry); err != nil {
-		return nil, fmt.Errorf("Failed to unmarshal protobuf: %s - %s", err.Error(), string(b))
+	protoEntry, err := unmarshalProtoEntry(buffer)
+	if err != nil {
+		return nil, err
 	}
 	entry := &Entry{
 		ID:           protoEntry.Id,
@@ -44,14 +44,18 @@ func (p *protoUnmarshaller) Unmarshal(buffer []byte) (*Entry, error) {
 		Level:        protoEntry.Level,
 		Contexts:     make([]Context, 0),
 		WriterOutput: protoEntry.WriterOutput,
+		Stack:        protoEntry.Stack,
+	}
+	if protoEntry.Caller != nil {
+		entry.Caller = &Caller{protoEntry.Caller.File, int(protoEntry.Caller.Line), protoEntry.Caller.Function}
 	}
 	event, err := p.getEvent(protoEntry.EventTypeName, protoEntry.Event)
 	if err != nil {
 		return nil, err
 	}
 	entry.Event = event
-	for contextTypeName, contextBytes := range protoEntry.ContextTypeNameToContext {
-		context, err := p.getContext(contextTypeName, contextBytes)
+	for _, protoContext := range getProtoContexts(protoEntry) {
+		context, err := p.getContext(protoContext.TypeName, protoContext.Context)
 		if err != nil {
 			return nil, err
 		}
@@ -60,9 +64,51 @@ func (p *protoUnmarshaller) Unmarshal(buffer []byte) (*Entry, error) {
 	return entry, nil
 }
 
+// unmarshalProtoEntry unmarshals buffer as base64, as written by ProtoMarshaller, and only if
+// that fails as binary, as written by BinaryProtoMarshaller. The error is that of base64.
+func unmarshalProtoEntry(buffer []byte) (*ProtoEntry, error) {
+	protoEntry := &ProtoEntry{}
+	decoder := base64.NewDecoder(base64.StdEncoding, bytes.NewBuffer(buffer))
+	bBuffer := bytes.NewBuffer(nil)
+	_, err := bBuffer.ReadFrom(decoder)
+	if err != nil {
+		err = fmt.Errorf("Failed to decode buffer: %s - %s", err.Error(), string(buffer))
+	} else if err = proto.Unmarshal(bBuffer.Bytes(), protoEntry); err != nil {
+		err = fmt.Errorf("Failed to unmarshal protobuf: %s - %s", err.Error(), bBuffer.String())
+	} else {
+		return protoEntry, nil
+	}
+	protoEntry = &ProtoEntry{}
+	if proto.Unmarshal(buffer, protoEntry) != nil {
+		return nil, err
+	}
+	return protoEntry, nil
+}
+
+// getProtoContexts returns the ordered Contexts of a ProtoEntry, falling back to
+// the Context map sorted by type name for ProtoEntry objects written by older Marshallers.
+func getProtoContexts(protoEntry *ProtoEntry) []*ProtoContext {
+	if len(protoEntry.Contexts) > 0 {
+		return protoEntry.Contexts
+	}
+	contextTypeNames := make([]string, 0, len(protoEntry.ContextTypeNameToContext))
+	for contextTypeName := range protoEntry.ContextTypeNameToContext {
+		contextTypeNames = append(contextTypeNames, contextTypeName)
+	}
+	sort.Strings(contextTypeNames)
+	protoContexts := make([]*ProtoContext, 0, len(contextTypeNames))
+	for _, contextTypeName := range contextTypeNames {
+		protoContexts = append(protoContexts, &ProtoContext{contextTypeName, protoEntry.ContextTypeNameToContext[contextTypeName]})
+	}
+	return protoContexts
+}
+
 func (p *protoUnmarshaller) getContext(objectType string, object []byte) (interface{}, error) {
 	reflectType, err := p.reflectTypeProvider.getContextReflectType(objectType)
 	if err != nil {
+		if isLenientError(p.options, err) {
+			return &RawContext{objectType, RawFormatProto, object}, nil
+		}
 		return nil, err
 	}
 	return p.getObject(reflectType, object)
@@ -71,6 +117,9 @@ func (p *protoUnmarshaller) getContext(objectType string, object []byte) (interf
 func (p *protoUnmarshaller) getEvent(objectType string, object []byte) (interface{}, error) {
 	reflectType, err := p.reflectTypeProvider.getEventReflectType(objectType)
 	if err != nil {
+		if isLenientError(p.options, err) {
+			return &RawEvent{objectType, RawFormatProto, object}, nil
+		}
 		return nil, err
 	}
 	return p.getObject(reflectType, object)
@@ -90,3 +139,193 @@ func (p *protoUnmarshaller) getObject(reflectType reflect.Type, object []byte) (
 	}
 	return reflect.ValueOf(objectPtr).Elem().Interface(), nil
 }
+
+type jsonUnmarshaller struct {
+	reflectTypeProvider *reflectTypeProvider
+	jsonKeys            *jsonKeys
+	options             JSONUnmarshallerOptions
+}
+
+func newJSONUnmarshaller(
+	specification *Specification,
+	options JSONUnmarshallerOptions,
+) (*jsonUnmarshaller, error) {
+	if err := validateJSONMarshallerOptions(options.MarshallerOptions); err != nil {
+		return nil, err
+	}
+	reflectTypeProvider, err := newReflectTypeProvider(specification)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonUnmarshaller{
+		reflectTypeProvider,
+		newJSONKeys(options.MarshallerOptions.Keys),
+		options,
+	}, nil
+}
+
+func (j *jsonUnmarshaller) Unmarshal(buffer []byte) (*Entry, error) {
+	m := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(buffer, &m); err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal json: %s - %s", err.Error(), string(buffer))
+	}
+	var id, levelString, eventKey, writerOutput string
+	for key, value := range map[string]*string{
+		j.jsonKeys.id:           &id,
+		j.jsonKeys.level:        &levelString,
+		j.jsonKeys.eventType:    &eventKey,
+		j.jsonKeys.writerOutput: &writerOutput,
+	} {
+		data, ok := m[key]
+		if !ok {
+			if key == j.jsonKeys.writerOutput && j.options.MarshallerOptions.OmitEmptyWriterOutput {
+				continue
+			}
+			return nil, fmt.Errorf("ledge: no %s key in %s", key, string(buffer))
+		}
+		if err := json.Unmarshal(data, value); err != nil {
+			return nil, err
+		}
+		delete(m, key)
+	}
+	timeData, ok := m[j.jsonKeys.time]
+	if !ok {
+		return nil, fmt.Errorf("ledge: no %s key in %s", j.jsonKeys.time, string(buffer))
+	}
+	delete(m, j.jsonKeys.time)
+	entryTime, err := parseJSONTime(timeData, j.options.MarshallerOptions)
+	if err != nil {
+		return nil, err
+	}
+	level, ok := Level_value[strings.ToUpper(levelString)]
+	if !ok {
+		return nil, fmt.Errorf("ledge: unknown level %s", levelString)
+	}
+	entry := &Entry{
+		ID:       id,
+		Time:     entryTime.UTC(),
+		Level:    Level(level),
+		Contexts: make([]Context, 0),
+	}
+	if writerOutput != "" {
+		entry.WriterOutput = []byte(writerOutput)
+	}
+	if data, ok := m[j.jsonKeys.caller]; ok {
+		entry.Caller = &Caller{}
+		if err := json.Unmarshal(data, entry.Caller); err != nil {
+			return nil, err
+		}
+		delete(m, j.jsonKeys.caller)
+	}
+	if data, ok := m[j.jsonKeys.stack]; ok {
+		if err := json.Unmarshal(data, &entry.Stack); err != nil {
+			return nil, err
+		}
+		delete(m, j.jsonKeys.stack)
+	}
+	eventData, ok := m[eventKey]
+	if !ok {
+		return nil, fmt.Errorf("ledge: no event for %s in %s", eventKey, string(buffer))
+	}
+	delete(m, eventKey)
+	event, err := j.getEvent(eventKey, eventData)
+	if err != nil {
+		return nil, err
+	}
+	entry.Event = event
+	if contextsKey := j.options.MarshallerOptions.ContextsKey; contextsKey != "" {
+		contextsData, ok := m[contextsKey]
+		m = make(map[string]json.RawMessage)
+		if ok {
+			if err := json.Unmarshal(contextsData, &m); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// json objects are unordered, so sort the Context keys to be deterministic
+	contextKeys := make([]string, 0, len(m))
+	for contextKey := range m {
+		contextKeys = append(contextKeys, contextKey)
+	}
+	sort.Strings(contextKeys)
+	for _, contextKey := range contextKeys {
+		context, err := j.getContext(contextKey, m[contextKey])
+		if err != nil {
+			return nil, err
+		}
+		entry.Contexts = append(entry.Contexts, context)
+	}
+	return entry, nil
+}
+
+func (j *jsonUnmarshaller) getContext(objectType string, object []byte) (interface{}, error) {
+	var reflectType reflect.Type
+	var err error
+	if j.options.MarshallerOptions.FullyQualifiedTypeNames {
+		reflectType, err = j.reflectTypeProvider.getContextReflectType(objectType)
+	} else {
+		reflectType, err = j.reflectTypeProvider.getContextReflectTypeForShortKey(objectType)
+	}
+	if err != nil {
+		if isLenientError(j.options.UnmarshallerOptions, err) {
+			return &RawContext{objectType, RawFormatJSON, object}, nil
+		}
+		return nil, err
+	}
+	return getJSONObject(reflectType, object)
+}
+
+func (j *jsonUnmarshaller) getEvent(objectType string, object []byte) (interface{}, error) {
+	var reflectType reflect.Type
+	var err error
+	if j.options.MarshallerOptions.FullyQualifiedTypeNames {
+		reflectType, err = j.reflectTypeProvider.getEventReflectType(objectType)
+	} else {
+		reflectType, err = j.reflectTypeProvider.getEventReflectTypeForShortKey(objectType)
+	}
+	if err != nil {
+		if isLenientError(j.options.UnmarshallerOptions, err) {
+			return &RawEvent{objectType, RawFormatJSON, object}, nil
+		}
+		return nil, err
+	}
+	return getJSONObject(reflectType, object)
+}
+
+func parseJSONTime(data []byte, options JSONMarshallerOptions) (time.Time, error) {
+	if options.TimeFormat == JSONTimeFormatUnixNano {
+		var unixNano int64
+		if err := json.Unmarshal(data, &unixNano); err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(0, unixNano), nil
+	}
+	var timeString string
+	if err := json.Unmarshal(data, &timeString); err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(getJSONTimeLayout(options), timeString)
+}
+
+func getJSONObject(reflectType reflect.Type, object []byte) (interface{}, error) {
+	if reflectType.Kind() == reflect.Ptr {
+		objectPtr := reflect.New(reflectType.Elem()).Interface()
+		if err := json.Unmarshal(object, objectPtr); err != nil {
+			return nil, err
+		}
+		return objectPtr, nil
+	}
+	objectPtr := reflect.New(reflectType).Interface()
+	if err := json.Unmarshal(object, objectPtr); err != nil {
+		return nil, err
+	}
+	return reflect.ValueOf(objectPtr).Elem().Interface(), nil
+}
+
+func isLenientError(options UnmarshallerOptions, err error) bool {
+	if !options.Lenient {
+		return false
+	}
+	_, ok := err.(*noReflectTypeError)
+	return ok
+}
@@ -178,5 +178,8 @@ func checkSingleEntriesEqual(expected *Entry, actual *Entry, checkID bool, check
 	if !reflect.DeepEqual(expected.WriterOutput, actual.WriterOutput) {
 		return fmt.Errorf("ledge: expected %+v, got %+v (WriterOutput)", expected, actual)
 	}
+	if !reflect.DeepEqual(expected.Caller, actual.Caller) {
+		return fmt.Errorf("ledge: expected %+v, got %+v (Caller)", expected, actual)
+	}
 	return nil
 }